@@ -1,14 +1,13 @@
 package main
 
 import (
-	"database/sql"
-	"errors"
+	"context"
 	"fmt"
 	"music/internal/app/service"
 	"music/internal/config"
 	"music/internal/logging"
 	"music/internal/rest"
-	"music/internal/storage/postgresql"
+	"music/internal/storage"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -16,11 +15,7 @@ import (
 
 	_ "music/docs"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
@@ -53,19 +48,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create db connection
-	db, err := newDB(cfg)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, "error connecting db", err)
-		os.Exit(1)
-	}
-
-	// Make Up migration
-	if err := makeMigration(db); err != nil {
-		fmt.Fprintln(os.Stderr, "migration error", err)
-		os.Exit(1)
-	}
-
 	// Create logger
 	logger, err := logging.GetLogger(cfg.LogLevel)
 	if err != nil {
@@ -74,11 +56,22 @@ func main() {
 	}
 	// fmt.Println("Log Level:", cfg.LogLevel)
 
+	// Open storage, database schema is migrated up
+	repos, err := storage.Open(context.Background(), *cfg, logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error opening storage", err)
+		os.Exit(1)
+	}
+	defer repos.Close()
+
 	r := mux.NewRouter()
-	repo := postgresql.NewSongRepo(db, logger)
-	svc := service.NewSongService(*cfg, logger, repo)
+	r.Use(rest.Timeout(cfg.RequestTimeout), rest.Author)
+	svc := service.NewSongService(*cfg, logger, repos.Songs, repos.Revisions, repos.Tx)
 	rest.NewSongHandler(*cfg, logger, svc).Register(r)
 
+	groupSvc := service.NewGroupService(*cfg, logger, repos.Groups, repos.Songs)
+	rest.NewGroupHandler(*cfg, logger, groupSvc).Register(r)
+
 	swagUrl := "./docs/doc.json"
 
 	r.PathPrefix("/docs/").Handler(httpSwagger.Handler(
@@ -88,56 +81,15 @@ func main() {
 		httpSwagger.DomID("swagger-ui"),
 	)).Methods(http.MethodGet)
 
+	// Write timeout leaves time to respond after the request deadline expires
 	server := http.Server{
 		Handler:           r,
 		Addr:              cfg.ServerAddr,
 		ReadTimeout:       1 * time.Second,
 		ReadHeaderTimeout: 1 * time.Second,
-		WriteTimeout:      1 * time.Second,
+		WriteTimeout:      cfg.RequestTimeout + 1*time.Second,
 		IdleTimeout:       1 * time.Second,
 	}
 	logger.Info("Server start", "listening on address:", cfg.ServerAddr)
 	server.ListenAndServe()
 }
-
-func newDB(cfg *config.Config) (*sql.DB, error) {
-	db, err := sql.Open("postgres", cfg.DbUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := db.Ping(); err != nil {
-		return nil, err
-	}
-
-	return db, nil
-}
-
-func makeMigration(db *sql.DB) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return err
-	}
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://./db/migrations/",
-		"music",
-		driver,
-	)
-	if err != nil {
-		return err
-	}
-
-	if _, _, err := m.Version(); err == nil {
-		// Migration has been made already
-		return nil
-	} else if errors.Is(err, migrate.ErrNilVersion) {
-		// No migrations has been applied, make migration
-		if err := m.Up(); err != nil {
-			return err
-		}
-		return nil
-	} else {
-		// Some error
-		return err
-	}
-}
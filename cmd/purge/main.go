@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"music/internal/app/service"
+	"music/internal/config"
+	"music/internal/logging"
+	"music/internal/storage"
+	"os"
+	"path/filepath"
+)
+
+// Purge permanently removes songs kept in the trash longer than
+// TRASH_RETENTION, run it periodically e.g. from cron.
+func main() {
+	// Find path for env file
+	dir, err := filepath.Abs(filepath.Dir(os.Args[0]))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "abs path", err)
+		os.Exit(1)
+	}
+
+	// config file must be in project root dir, compiled bin must be in /bin dir!!!
+	configPath, err := filepath.Abs(dir + "/../.env")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "composing path", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error loading config", err)
+		os.Exit(1)
+	}
+
+	logger, err := logging.GetLogger(cfg.LogLevel)
+	if err != nil {
+		fmt.Printf("getting logger: %s\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	repos, err := storage.Open(ctx, *cfg, logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error opening storage", err)
+		os.Exit(1)
+	}
+	defer repos.Close()
+
+	svc := service.NewSongService(*cfg, logger, repos.Songs, repos.Revisions, repos.Tx)
+
+	n, err := svc.Purge(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "purge error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("Trash purged", "songs removed", n, "retention", cfg.TrashRetention)
+}
@@ -0,0 +1,16 @@
+// Package db embeds the database migrations, so binaries and tests do not
+// depend on the working directory.
+package db
+
+import "embed"
+
+// Migrations holds migrations of the PostgreSQL storage under migrations.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// SQLiteMigrations holds migrations of the SQLite storage under
+// sqlite_migrations.
+//
+//go:embed sqlite_migrations/*.sql
+var SQLiteMigrations embed.FS
@@ -0,0 +1,29 @@
+DROP VIEW IF EXISTS public.songs_view;
+
+ALTER TABLE public.songs ADD COLUMN group_name varchar(50);
+ALTER TABLE public.songs ADD COLUMN song_text text;
+
+UPDATE public.songs s SET group_name = g.group_name
+FROM public.groups g
+WHERE g.id = s.group_id;
+
+UPDATE public.songs s SET song_text = COALESCE(
+    (SELECT string_agg(v.verse_text, E'\n\n' ORDER BY v.num)
+    FROM public.verses v
+    WHERE v.song_id = s.id),
+    ''
+);
+
+ALTER TABLE public.songs ALTER COLUMN group_name SET NOT NULL;
+ALTER TABLE public.songs ALTER COLUMN song_text SET NOT NULL;
+
+DROP TABLE IF EXISTS public.verses;
+
+DROP INDEX IF EXISTS group_id_idx;
+DROP INDEX IF EXISTS group_name_idx;
+
+ALTER TABLE public.songs DROP COLUMN group_id;
+
+DROP TABLE IF EXISTS public.groups;
+
+CREATE INDEX group_name_idx on public.songs(group_name);
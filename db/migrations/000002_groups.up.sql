@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS public.groups(
+    id SERIAL PRIMARY KEY,
+    group_name varchar(50) NOT NULL
+);
+
+INSERT INTO public.groups (group_name)
+SELECT DISTINCT group_name FROM public.songs ORDER BY group_name;
+
+ALTER TABLE public.songs ADD COLUMN group_id integer REFERENCES public.groups(id) ON DELETE RESTRICT;
+
+UPDATE public.songs s SET group_id = g.id
+FROM public.groups g
+WHERE g.group_name = s.group_name;
+
+ALTER TABLE public.songs ALTER COLUMN group_id SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.verses(
+    song_id integer NOT NULL REFERENCES public.songs(id) ON DELETE CASCADE,
+    num integer NOT NULL,
+    verse_text text NOT NULL,
+    PRIMARY KEY (song_id, num)
+);
+
+INSERT INTO public.verses (song_id, num, verse_text)
+SELECT s.id, v.num, v.verse_text
+FROM public.songs s,
+    regexp_split_to_table(s.song_text, E'\n\n') WITH ORDINALITY AS v(verse_text, num);
+
+DROP INDEX IF EXISTS group_name_idx;
+
+ALTER TABLE public.songs DROP COLUMN group_name;
+ALTER TABLE public.songs DROP COLUMN song_text;
+
+CREATE INDEX group_name_idx on public.groups(group_name);
+CREATE INDEX group_id_idx on public.songs(group_id);
+
+-- Assembles the full song record: group name and verses joined back into text.
+CREATE VIEW public.songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT string_agg(v.verse_text, E'\n\n' ORDER BY v.num)
+        FROM public.verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link
+FROM public.songs s
+JOIN public.groups g ON g.id = s.group_id;
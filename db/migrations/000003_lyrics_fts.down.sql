@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS verse_tsv_idx;
+
+ALTER TABLE public.verses DROP COLUMN IF EXISTS verse_tsv;
@@ -0,0 +1,4 @@
+ALTER TABLE public.verses ADD COLUMN verse_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('english'::regconfig, verse_text)) STORED;
+
+CREATE INDEX verse_tsv_idx on public.verses USING GIN (verse_tsv);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS song_name_trgm_idx;
+DROP INDEX IF EXISTS group_name_trgm_idx;
+
+DROP EXTENSION IF EXISTS pg_trgm;
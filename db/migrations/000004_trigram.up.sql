@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX group_name_trgm_idx on public.groups USING GIN (group_name gin_trgm_ops);
+CREATE INDEX song_name_trgm_idx on public.songs USING GIN (song_name gin_trgm_ops);
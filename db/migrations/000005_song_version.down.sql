@@ -0,0 +1,20 @@
+DROP VIEW IF EXISTS public.songs_view;
+
+CREATE VIEW public.songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT string_agg(v.verse_text, E'\n\n' ORDER BY v.num)
+        FROM public.verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link
+FROM public.songs s
+JOIN public.groups g ON g.id = s.group_id;
+
+ALTER TABLE public.songs DROP COLUMN IF EXISTS version;
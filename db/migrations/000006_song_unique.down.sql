@@ -0,0 +1 @@
+DROP INDEX IF EXISTS group_song_name_uniq;
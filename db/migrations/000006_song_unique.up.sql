@@ -0,0 +1,10 @@
+-- Songs duplicated in a group before the constraint: the first one keeps
+-- the name, later copies get their id appended, e.g. "Uprising (42)"
+UPDATE public.songs s SET
+    song_name = left(s.song_name, 200 - length(' (' || s.id || ')')) || ' (' || s.id || ')'
+WHERE EXISTS (
+    SELECT 1 FROM public.songs o
+    WHERE o.group_id = s.group_id AND lower(o.song_name) = lower(s.song_name) AND o.id < s.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS group_song_name_uniq on public.songs (group_id, lower(song_name));
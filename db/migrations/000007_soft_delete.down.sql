@@ -0,0 +1,29 @@
+DROP VIEW IF EXISTS public.songs_view;
+DROP VIEW IF EXISTS public.all_songs_view;
+
+-- Songs in the trash would become visible again
+DELETE FROM public.songs WHERE deleted_at IS NOT NULL;
+
+CREATE VIEW public.songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT string_agg(v.verse_text, E'\n\n' ORDER BY v.num)
+        FROM public.verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version
+FROM public.songs s
+JOIN public.groups g ON g.id = s.group_id;
+
+DROP INDEX IF EXISTS group_song_name_uniq;
+CREATE UNIQUE INDEX group_song_name_uniq on public.songs (group_id, lower(song_name));
+
+DROP INDEX IF EXISTS songs_deleted_at_idx;
+ALTER TABLE public.songs DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,32 @@
+DROP VIEW IF EXISTS public.songs_view;
+DROP VIEW IF EXISTS public.all_songs_view;
+
+UPDATE public.verses SET verse_text = '[' || initcap(kind) || E']\n' || verse_text
+WHERE kind <> 'verse';
+
+ALTER TABLE public.verses DROP COLUMN kind;
+
+CREATE VIEW public.all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT string_agg(v.verse_text, E'\n\n' ORDER BY v.num)
+        FROM public.verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM public.songs s
+JOIN public.groups g ON g.id = s.group_id;
+
+CREATE VIEW public.songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM public.all_songs_view
+WHERE deleted_at IS NULL;
@@ -0,0 +1,43 @@
+ALTER TABLE public.verses ADD COLUMN kind varchar(10) NOT NULL DEFAULT 'verse'
+    CHECK (kind IN ('verse', 'chorus', 'bridge', 'intro', 'outro'));
+
+-- Verses starting with an annotation line like [Chorus] take its type, the
+-- line itself is dropped. An annotation alone, like [Chorus x2], stays the
+-- text of a verse. Texts of songs are normalized on the next update.
+UPDATE public.verses SET
+    kind = lower(substring(verse_text from '(?i)^\[\s*(verse|chorus|bridge|intro|outro)')),
+    verse_text = regexp_replace(verse_text, '^[^\n]*\n', '')
+WHERE verse_text ~* '^\[\s*(verse|chorus|bridge|intro|outro)\M[^\]\n]*\][ \t\r]*\n[ \t\r]*[^ \t\r\n]';
+
+DROP VIEW IF EXISTS public.songs_view;
+DROP VIEW IF EXISTS public.all_songs_view;
+
+CREATE VIEW public.all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT string_agg(
+            CASE
+                WHEN v.kind = 'verse' THEN v.verse_text
+                ELSE '[' || initcap(v.kind) || E']\n' || v.verse_text
+            END,
+            E'\n\n' ORDER BY v.num)
+        FROM public.verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM public.songs s
+JOIN public.groups g ON g.id = s.group_id;
+
+CREATE VIEW public.songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM public.all_songs_view
+WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS group_name_idx;
+DROP INDEX IF EXISTS song_name_idx;
+DROP INDEX IF EXISTS release_date_idx;
+
+DROP TABLE IF EXISTS songs;
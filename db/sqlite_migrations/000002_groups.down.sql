@@ -0,0 +1,41 @@
+DROP VIEW IF EXISTS songs_view;
+
+DROP INDEX IF EXISTS group_id_idx;
+DROP INDEX IF EXISTS group_name_idx;
+DROP INDEX IF EXISTS song_name_idx;
+DROP INDEX IF EXISTS release_date_idx;
+
+CREATE TABLE old_songs(
+    id integer PRIMARY KEY AUTOINCREMENT,
+    group_name varchar(50) NOT NULL,
+    song_name varchar(200) NOT NULL,
+    release_date date NOT NULL,
+    song_text text NOT NULL,
+    link text NOT NULL
+);
+
+INSERT INTO old_songs (id, group_name, song_name, release_date, song_text, link)
+SELECT
+    s.id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(v.verse_text, char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ),
+    s.link
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+DROP TABLE IF EXISTS verses;
+DROP TABLE IF EXISTS songs;
+DROP TABLE IF EXISTS groups;
+
+ALTER TABLE old_songs RENAME TO songs;
+
+CREATE INDEX group_name_idx on songs(group_name);
+CREATE INDEX song_name_idx on songs(song_name);
+CREATE INDEX release_date_idx on songs(release_date);
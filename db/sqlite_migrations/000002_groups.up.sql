@@ -0,0 +1,74 @@
+CREATE TABLE IF NOT EXISTS groups(
+    id integer PRIMARY KEY AUTOINCREMENT,
+    group_name varchar(50) NOT NULL
+);
+
+INSERT INTO groups (group_name)
+SELECT DISTINCT group_name FROM songs ORDER BY group_name;
+
+-- SQLite can not add a NOT NULL reference, the table is rebuilt
+DROP INDEX IF EXISTS group_name_idx;
+DROP INDEX IF EXISTS song_name_idx;
+DROP INDEX IF EXISTS release_date_idx;
+
+ALTER TABLE songs RENAME TO old_songs;
+
+CREATE TABLE songs(
+    id integer PRIMARY KEY AUTOINCREMENT,
+    group_id integer NOT NULL REFERENCES groups(id) ON DELETE RESTRICT,
+    song_name varchar(200) NOT NULL,
+    release_date date NOT NULL,
+    link text NOT NULL
+);
+
+INSERT INTO songs (id, group_id, song_name, release_date, link)
+SELECT s.id, g.id, s.song_name, s.release_date, s.link
+FROM old_songs s
+JOIN groups g ON g.group_name = s.group_name;
+
+CREATE TABLE IF NOT EXISTS verses(
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    num integer NOT NULL,
+    verse_text text NOT NULL,
+    PRIMARY KEY (song_id, num)
+);
+
+-- Split song texts by an empty line
+INSERT INTO verses (song_id, num, verse_text)
+WITH RECURSIVE split(song_id, num, verse_text, rest) AS (
+    SELECT id, 0, '', song_text || char(10, 10) FROM old_songs
+    UNION ALL
+    SELECT
+        song_id,
+        num + 1,
+        substr(rest, 1, instr(rest, char(10, 10)) - 1),
+        substr(rest, instr(rest, char(10, 10)) + 2)
+    FROM split
+    WHERE rest <> ''
+)
+SELECT song_id, num, verse_text FROM split WHERE num > 0;
+
+DROP TABLE old_songs;
+
+CREATE INDEX song_name_idx on songs(song_name);
+CREATE INDEX release_date_idx on songs(release_date);
+CREATE INDEX group_name_idx on groups(group_name);
+CREATE INDEX group_id_idx on songs(group_id);
+
+-- Assembles the full song record: group name and verses joined back into text.
+CREATE VIEW songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(v.verse_text, char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
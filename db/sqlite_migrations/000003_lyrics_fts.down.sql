@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS verses_fts_insert;
+DROP TRIGGER IF EXISTS verses_fts_delete;
+DROP TRIGGER IF EXISTS verses_fts_update;
+
+DROP TABLE IF EXISTS verses_fts;
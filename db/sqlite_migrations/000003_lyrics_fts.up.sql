@@ -0,0 +1,24 @@
+-- Full text index of verses, kept in sync by triggers. It stores its own
+-- copy of the text: rowids of verses are not stable across VACUUM.
+CREATE VIRTUAL TABLE verses_fts USING fts5(
+    song_id UNINDEXED,
+    num UNINDEXED,
+    verse_text,
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO verses_fts (song_id, num, verse_text)
+SELECT song_id, num, verse_text FROM verses;
+
+CREATE TRIGGER verses_fts_insert AFTER INSERT ON verses BEGIN
+    INSERT INTO verses_fts (song_id, num, verse_text) VALUES (new.song_id, new.num, new.verse_text);
+END;
+
+CREATE TRIGGER verses_fts_delete AFTER DELETE ON verses BEGIN
+    DELETE FROM verses_fts WHERE song_id = old.song_id AND num = old.num;
+END;
+
+CREATE TRIGGER verses_fts_update AFTER UPDATE ON verses BEGIN
+    DELETE FROM verses_fts WHERE song_id = old.song_id AND num = old.num;
+    INSERT INTO verses_fts (song_id, num, verse_text) VALUES (new.song_id, new.num, new.verse_text);
+END;
@@ -0,0 +1,3 @@
+-- Trigram similarity is computed by functions registered with the driver,
+-- SQLite has no index for it.
+SELECT 1;
@@ -0,0 +1,20 @@
+DROP VIEW IF EXISTS songs_view;
+
+CREATE VIEW songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(v.verse_text, char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+ALTER TABLE songs DROP COLUMN version;
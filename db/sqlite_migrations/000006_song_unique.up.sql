@@ -0,0 +1,12 @@
+-- Songs duplicated in a group before the constraint: the first one keeps
+-- the name, later copies get their id appended, e.g. "Uprising (42)"
+UPDATE songs SET
+    song_name = substr(song_name, 1, 200 - length(' (' || id || ')')) || ' (' || id || ')'
+WHERE EXISTS (
+    SELECT 1 FROM songs o
+    WHERE o.group_id = songs.group_id AND lower(o.song_name) = lower(songs.song_name) AND o.id < songs.id
+);
+
+-- lower folds ASCII letters only, the repository also checks names folded
+-- by Go before writing
+CREATE UNIQUE INDEX IF NOT EXISTS group_song_name_uniq on songs (group_id, lower(song_name));
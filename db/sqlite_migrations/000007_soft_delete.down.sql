@@ -0,0 +1,29 @@
+DROP VIEW IF EXISTS songs_view;
+DROP VIEW IF EXISTS all_songs_view;
+
+-- Songs in the trash would become visible again
+DELETE FROM songs WHERE deleted_at IS NOT NULL;
+
+CREATE VIEW songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(v.verse_text, char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+DROP INDEX IF EXISTS group_song_name_uniq;
+CREATE UNIQUE INDEX group_song_name_uniq on songs (group_id, lower(song_name));
+
+DROP INDEX IF EXISTS songs_deleted_at_idx;
+ALTER TABLE songs DROP COLUMN deleted_at;
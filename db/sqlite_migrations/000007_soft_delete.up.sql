@@ -0,0 +1,34 @@
+ALTER TABLE songs ADD COLUMN deleted_at datetime;
+
+CREATE INDEX songs_deleted_at_idx on songs (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Deleted songs do not hold their names
+DROP INDEX IF EXISTS group_song_name_uniq;
+CREATE UNIQUE INDEX group_song_name_uniq on songs (group_id, lower(song_name)) WHERE deleted_at IS NULL;
+
+DROP VIEW IF EXISTS songs_view;
+
+CREATE VIEW all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(v.verse_text, char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+CREATE VIEW songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM all_songs_view
+WHERE deleted_at IS NULL;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS song_revisions (
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    rev integer NOT NULL,
+    action varchar(10) NOT NULL,
+    author varchar(100) NOT NULL DEFAULT '',
+    created_at datetime NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    group_id integer NOT NULL,
+    group_name varchar(50) NOT NULL,
+    song_name varchar(200) NOT NULL,
+    release_date date NOT NULL,
+    song_text text NOT NULL,
+    link text NOT NULL,
+    PRIMARY KEY (song_id, rev)
+);
+
+-- Current state of existing songs is their first known revision
+INSERT INTO song_revisions
+    (song_id, rev, action, group_id, group_name, song_name, release_date, song_text, link)
+SELECT
+    id, version,
+    CASE
+        WHEN deleted_at IS NOT NULL THEN 'delete'
+        WHEN version = 1 THEN 'create'
+        ELSE 'update'
+    END,
+    group_id, group_name, song_name, release_date, song_text, link
+FROM all_songs_view;
@@ -0,0 +1,32 @@
+DROP VIEW IF EXISTS songs_view;
+DROP VIEW IF EXISTS all_songs_view;
+
+UPDATE verses SET verse_text = '[' || upper(substr(kind, 1, 1)) || substr(kind, 2) || ']' || char(10) || verse_text
+WHERE kind <> 'verse';
+
+ALTER TABLE verses DROP COLUMN kind;
+
+CREATE VIEW all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(v.verse_text, char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+CREATE VIEW songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM all_songs_view
+WHERE deleted_at IS NULL;
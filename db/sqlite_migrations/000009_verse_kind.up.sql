@@ -0,0 +1,66 @@
+ALTER TABLE verses ADD COLUMN kind varchar(10) NOT NULL DEFAULT 'verse'
+    CHECK (kind IN ('verse', 'chorus', 'bridge', 'intro', 'outro'));
+
+-- Verses starting with an annotation line like [Chorus] take its type, the
+-- line itself is dropped. An annotation alone, like [Chorus x2], stays the
+-- text of a verse. Texts of songs are normalized on the next update.
+WITH lines AS (
+    SELECT
+        song_id,
+        num,
+        rtrim(substr(verse_text, 1, instr(verse_text || char(10), char(10)) - 1), ' ' || char(9, 13)) AS line,
+        substr(verse_text, instr(verse_text || char(10), char(10)) + 1) AS rest
+    FROM verses
+), heads AS (
+    SELECT
+        song_id,
+        num,
+        line,
+        rest,
+        trim(substr(rest, 1, instr(rest || char(10), char(10)) - 1), ' ' || char(9, 13)) AS next
+    FROM lines
+), kinds (kind) AS (
+    VALUES ('verse'), ('chorus'), ('bridge'), ('intro'), ('outro')
+)
+UPDATE verses SET
+    kind = k.kind,
+    verse_text = h.rest
+FROM heads h
+JOIN kinds k ON lower(ltrim(substr(h.line, 2), ' ')) GLOB k.kind || '[^a-z]*'
+WHERE verses.song_id = h.song_id
+    AND verses.num = h.num
+    AND h.line LIKE '[%]'
+    AND h.next <> '';
+
+DROP VIEW IF EXISTS songs_view;
+DROP VIEW IF EXISTS all_songs_view;
+
+CREATE VIEW all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(
+            CASE
+                WHEN v.kind = 'verse' THEN v.verse_text
+                ELSE '[' || upper(substr(v.kind, 1, 1)) || substr(v.kind, 2) || ']' || char(10) || v.verse_text
+            END,
+            char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+CREATE VIEW songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM all_songs_view
+WHERE deleted_at IS NULL;
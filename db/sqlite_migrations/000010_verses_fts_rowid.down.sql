@@ -0,0 +1,76 @@
+DROP TRIGGER IF EXISTS verses_fts_insert;
+DROP TRIGGER IF EXISTS verses_fts_delete;
+DROP TRIGGER IF EXISTS verses_fts_update;
+
+DROP TABLE IF EXISTS verses_fts;
+
+DROP VIEW IF EXISTS songs_view;
+DROP VIEW IF EXISTS all_songs_view;
+
+CREATE TABLE old_verses(
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    num integer NOT NULL,
+    verse_text text NOT NULL,
+    kind varchar(10) NOT NULL DEFAULT 'verse'
+        CHECK (kind IN ('verse', 'chorus', 'bridge', 'intro', 'outro')),
+    PRIMARY KEY (song_id, num)
+);
+
+INSERT INTO old_verses (song_id, num, verse_text, kind)
+SELECT song_id, num, verse_text, kind FROM verses;
+
+DROP TABLE verses;
+ALTER TABLE old_verses RENAME TO verses;
+
+CREATE VIRTUAL TABLE verses_fts USING fts5(
+    song_id UNINDEXED,
+    num UNINDEXED,
+    verse_text,
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO verses_fts (song_id, num, verse_text)
+SELECT song_id, num, verse_text FROM verses;
+
+CREATE TRIGGER verses_fts_insert AFTER INSERT ON verses BEGIN
+    INSERT INTO verses_fts (song_id, num, verse_text) VALUES (new.song_id, new.num, new.verse_text);
+END;
+
+CREATE TRIGGER verses_fts_delete AFTER DELETE ON verses BEGIN
+    DELETE FROM verses_fts WHERE song_id = old.song_id AND num = old.num;
+END;
+
+CREATE TRIGGER verses_fts_update AFTER UPDATE ON verses BEGIN
+    DELETE FROM verses_fts WHERE song_id = old.song_id AND num = old.num;
+    INSERT INTO verses_fts (song_id, num, verse_text) VALUES (new.song_id, new.num, new.verse_text);
+END;
+
+CREATE VIEW all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(
+            CASE
+                WHEN v.kind = 'verse' THEN v.verse_text
+                ELSE '[' || upper(substr(v.kind, 1, 1)) || substr(v.kind, 2) || ']' || char(10) || v.verse_text
+            END,
+            char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+CREATE VIEW songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM all_songs_view
+WHERE deleted_at IS NULL;
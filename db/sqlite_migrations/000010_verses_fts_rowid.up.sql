@@ -0,0 +1,79 @@
+-- The full text index reads verses by rowid instead of keeping its own copy
+-- filtered by an unindexed song id. Verses get an explicit integer primary
+-- key for that: an implicit rowid may change on VACUUM.
+DROP TRIGGER IF EXISTS verses_fts_insert;
+DROP TRIGGER IF EXISTS verses_fts_delete;
+DROP TRIGGER IF EXISTS verses_fts_update;
+
+DROP TABLE IF EXISTS verses_fts;
+
+DROP VIEW IF EXISTS songs_view;
+DROP VIEW IF EXISTS all_songs_view;
+
+CREATE TABLE new_verses(
+    id integer PRIMARY KEY,
+    song_id integer NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    num integer NOT NULL,
+    verse_text text NOT NULL,
+    kind varchar(10) NOT NULL DEFAULT 'verse'
+        CHECK (kind IN ('verse', 'chorus', 'bridge', 'intro', 'outro')),
+    UNIQUE (song_id, num)
+);
+
+INSERT INTO new_verses (song_id, num, verse_text, kind)
+SELECT song_id, num, verse_text, kind FROM verses ORDER BY song_id, num;
+
+DROP TABLE verses;
+ALTER TABLE new_verses RENAME TO verses;
+
+CREATE VIRTUAL TABLE verses_fts USING fts5(
+    verse_text,
+    content = 'verses',
+    content_rowid = 'id',
+    tokenize = 'porter unicode61'
+);
+
+INSERT INTO verses_fts (verses_fts) VALUES ('rebuild');
+
+CREATE TRIGGER verses_fts_insert AFTER INSERT ON verses BEGIN
+    INSERT INTO verses_fts (rowid, verse_text) VALUES (new.id, new.verse_text);
+END;
+
+CREATE TRIGGER verses_fts_delete AFTER DELETE ON verses BEGIN
+    INSERT INTO verses_fts (verses_fts, rowid, verse_text) VALUES ('delete', old.id, old.verse_text);
+END;
+
+CREATE TRIGGER verses_fts_update AFTER UPDATE OF verse_text ON verses BEGIN
+    INSERT INTO verses_fts (verses_fts, rowid, verse_text) VALUES ('delete', old.id, old.verse_text);
+    INSERT INTO verses_fts (rowid, verse_text) VALUES (new.id, new.verse_text);
+END;
+
+CREATE VIEW all_songs_view AS
+SELECT
+    s.id,
+    s.group_id,
+    g.group_name,
+    s.song_name,
+    s.release_date,
+    COALESCE(
+        (SELECT group_concat(
+            CASE
+                WHEN v.kind = 'verse' THEN v.verse_text
+                ELSE '[' || upper(substr(v.kind, 1, 1)) || substr(v.kind, 2) || ']' || char(10) || v.verse_text
+            END,
+            char(10, 10) ORDER BY v.num)
+        FROM verses v
+        WHERE v.song_id = s.id),
+        ''
+    ) AS song_text,
+    s.link,
+    s.version,
+    s.deleted_at
+FROM songs s
+JOIN groups g ON g.id = s.group_id;
+
+CREATE VIEW songs_view AS
+SELECT
+    id, group_id, group_name, song_name, release_date, song_text, link, version
+FROM all_songs_view
+WHERE deleted_at IS NULL;
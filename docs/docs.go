@@ -15,9 +15,58 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/songs": {
+        "/groups": {
+            "get": {
+                "description": "List groups ordered by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Группы"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 10 by default",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Group"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Create new record",
+                "description": "Create new group",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,7 +74,7 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Фонотека"
+                    "Группы"
                 ],
                 "parameters": [
                     {
@@ -34,7 +83,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SongDetails"
+                            "$ref": "#/definitions/models.GroupParams"
                         }
                     }
                 ],
@@ -42,7 +91,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.Group"
                         }
                     },
                     "400": {
@@ -56,19 +105,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
-                    },
-                    "502": {
-                        "description": "Bad Gateway",
-                        "schema": {
-                            "$ref": "#/definitions/rest.ErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/songs/page/{page_num}/records/{per_page}": {
+        "/groups/{id}": {
             "get": {
-                "description": "Поиск по фонотеке",
+                "description": "Get group",
                 "consumes": [
                     "application/json"
                 ],
@@ -76,62 +119,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Фонотека"
+                    "Группы"
                 ],
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Page number from 0",
-                        "name": "page_num",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Records per page",
-                        "name": "per_page",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Searching group name",
-                        "name": "group_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Song name",
-                        "name": "song_name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Release date (example 17.06.2006)",
-                        "name": "release_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Song text",
-                        "name": "song_text",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Link",
-                        "name": "link",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "ok",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Song"
-                            }
+                            "$ref": "#/definitions/models.Group"
                         }
                     },
                     "400": {
@@ -153,11 +156,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/songs/{id}": {
+            },
             "put": {
-                "description": "Update record",
+                "description": "Rename group",
                 "consumes": [
                     "application/json"
                 ],
@@ -165,12 +166,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Фонотека"
+                    "Группы"
                 ],
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Song ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -181,7 +182,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateParams"
+                            "$ref": "#/definitions/models.GroupParams"
                         }
                     }
                 ],
@@ -189,7 +190,7 @@ const docTemplate = `{
                     "200": {
                         "description": "ok",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.Group"
                         }
                     },
                     "400": {
@@ -213,7 +214,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete record",
+                "description": "Delete group, the group must have no songs, including songs in the trash",
                 "consumes": [
                     "application/json"
                 ],
@@ -221,12 +222,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Фонотека"
+                    "Группы"
                 ],
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Song ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -248,6 +249,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Group has songs",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal error",
                         "schema": {
@@ -257,9 +264,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}/verse/{vid}": {
+        "/groups/{id}/songs": {
             "get": {
-                "description": "Получить куплет песни",
+                "description": "List songs of the group ordered by release date",
                 "consumes": [
                     "application/json"
                 ],
@@ -267,29 +274,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Фонотека"
+                    "Группы"
                 ],
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Song ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Номер куплета, начиная с 1",
-                        "name": "vid",
-                        "in": "path",
-                        "required": true
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 10 by default",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "ok",
                         "schema": {
-                            "$ref": "#/definitions/models.Verse"
+                            "$ref": "#/definitions/models.SearchPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 page links"
+                            }
                         }
                     },
                     "400": {
@@ -312,128 +330,1875 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Song": {
-            "type": "object",
-            "required": [
-                "group",
-                "link",
-                "name",
-                "releaseDate",
-                "text"
-            ],
-            "properties": {
-                "group": {
-                    "description": "Group name",
-                    "type": "string",
-                    "example": "Muse"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "link": {
-                    "description": "URL link",
-                    "type": "string",
-                    "example": "http://example.org"
-                },
-                "name": {
-                    "description": "Song name",
-                    "type": "string",
-                    "example": "Supermassive Black Hole"
-                },
-                "releaseDate": {
-                    "description": "Release date in 02.01.2006 format",
-                    "type": "string",
-                    "example": "16.07.2006"
-                },
-                "text": {
-                    "description": "Song text",
-                    "type": "string",
-                    "example": "Some text\n"
-                }
-            }
-        },
-        "models.SongDetails": {
-            "type": "object",
-            "required": [
-                "group",
-                "song"
-            ],
-            "properties": {
-                "group": {
-                    "description": "Group name",
-                    "type": "string",
-                    "example": "Muse"
-                },
-                "song": {
-                    "description": "Song name",
-                    "type": "string",
-                    "example": "Supermassive Black Hole"
-                }
-            }
-        },
-        "models.UpdateParams": {
-            "type": "object",
-            "required": [
-                "group_name",
-                "link",
-                "release_date",
-                "song_name",
-                "song_text"
-            ],
-            "properties": {
-                "group_name": {
-                    "description": "Group name",
-                    "type": "string",
-                    "example": "Muse"
-                },
-                "link": {
-                    "description": "URL link",
-                    "type": "string",
-                    "example": "http://example.org"
-                },
-                "release_date": {
-                    "description": "Release date in 02.01.2006 format",
-                    "type": "string",
-                    "example": "16.07.2006"
-                },
-                "song_name": {
-                    "description": "Song name",
-                    "type": "string",
-                    "example": "Supermassive Black Hole"
-                },
-                "song_text": {
-                    "description": "Song text",
-                    "type": "string",
-                    "example": "Some text\n\n Some text2\n"
-                }
-            }
-        },
-        "models.Verse": {
-            "type": "object",
-            "properties": {
-                "num": {
-                    "description": "verse number",
-                    "type": "string",
-                    "example": "1"
-                },
-                "text": {
-                    "description": "verse text",
-                    "type": "string",
-                    "example": "Some text\n"
-                }
-            }
         },
-        "rest.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "description": "Error string",
-                    "type": "string",
-                    "example": "error description"
+        "/songs": {
+            "get": {
+                "description": "Список песен с курсорной пагинацией. Takes the same filters and sort as the page search,\npass next_cursor of the response to get the next page with the same filters and sort.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 10 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, - for descending, e.g. release_date,-song_name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Searching group name",
+                        "name": "group_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song name",
+                        "name": "song_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Release date (example 17.06.2006)",
+                        "name": "release_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song text",
+                        "name": "song_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link",
+                        "name": "link",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create new record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "description": "input data",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SongDetails"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Song already exists in the group",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/page/{page_num}/records/{per_page}": {
+            "get": {
+                "description": "Поиск по фонотеке. A filter key may carry an operator, e.g. song_name[ilike]=black,\nrelease_date[gte]=01.01.2005, group_name[in]=Muse,Queen. Text fields support eq (default),\nieq, prefix, contains, ilike and in, group_name and song_name also support typo-tolerant similar;\ngroup_id and release_date support eq, gt, gte, lt, lte and in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page",
+                        "name": "per_page",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Group ID",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Searching group name",
+                        "name": "group_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song name",
+                        "name": "song_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Release date (example 17.06.2006)",
+                        "name": "release_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song text",
+                        "name": "song_text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated sort fields, - for descending, e.g. release_date,-song_name. Allowed: id, group_id, group_name, song_name, release_date, link",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok, Link header refers to first, prev, next and last pages",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 page links"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/search": {
+            "get": {
+                "description": "Full-text lyrics search ordered by relevance, headlines highlight matching lines",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Web search style query, e.g. soul or alight -baby",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 10 by default",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LyricsMatch"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/suggest": {
+            "get": {
+                "description": "Autocomplete song titles, typo-tolerant: finds songs whose name or group name resemble the query",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Query, e.g. Supermasive Black Hol",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of suggestions, 10 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Suggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Get record with full details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "input data",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateParams"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version to change",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Song already exists in the group",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition failed",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move record to the trash, it can be restored until purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version to delete",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok"
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition failed",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update record with JSON Merge Patch (RFC 7396), only supplied fields are changed",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change",
+                        "name": "json",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PatchParams"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version to change",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Song already exists in the group",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition failed",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported media type",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/diff": {
+            "get": {
+                "description": "Построчное сравнение текста двух ревизий песни по куплетам. format=unified или Accept: text/x-diff возвращает unified diff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/x-diff"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Older revision number",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Newer revision number",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or unified",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.SongDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/lyrics": {
+            "get": {
+                "description": "Получить куплеты песни из диапазона номеров постранично",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Диапазон номеров куплетов: 2-4, 3 или 2-, по умолчанию все",
+                        "name": "verses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Заменить повторы припева ссылками вида [Chorus x3]",
+                        "name": "compact",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Verses per page, 10 by default",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.LyricsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/restore": {
+            "post": {
+                "description": "Restore deleted record from the trash",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Song already exists in the group",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions": {
+            "get": {
+                "description": "История изменений песни, последняя ревизия первой. Version каждой записи - номер ревизии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 10 by default",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}": {
+            "get": {
+                "description": "Ревизия песни: её состояние после изменения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Revision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/revisions/{rev}/revert": {
+            "post": {
+                "description": "Вернуть песню к содержимому ревизии, изменение записывается новой ревизией",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the song version to change",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Song already exists in the group",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition failed",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/sections": {
+            "get": {
+                "description": "Получить текст песни по частям: куплеты, припевы, бриджи, вступление и концовка",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Section"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/verse/{vid}": {
+            "get": {
+                "description": "Получить куплет песни",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Song ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер куплета, начиная с 1",
+                        "name": "vid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/models.Verse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/songs": {
+            "get": {
+                "description": "List deleted records, the most recently deleted first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Фонотека"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number from 0",
+                        "name": "page_num",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Records per page, 10 by default",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TrashedSong"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.DiffOp": {
+            "type": "string",
+            "enum": [
+                "equal",
+                "added",
+                "removed",
+                "changed"
+            ],
+            "x-enum-varnames": [
+                "DiffEqual",
+                "DiffAdded",
+                "DiffRemoved",
+                "DiffChanged"
+            ]
+        },
+        "models.Group": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                }
+            }
+        },
+        "models.GroupParams": {
+            "type": "object",
+            "required": [
+                "group_name"
+            ],
+            "properties": {
+                "group_name": {
+                    "description": "Group name",
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "Muse"
+                }
+            }
+        },
+        "models.LineDiff": {
+            "type": "object",
+            "properties": {
+                "new": {
+                    "description": "Line in the newer revision, empty if removed",
+                    "type": "string",
+                    "example": "You set my soul on fire"
+                },
+                "old": {
+                    "description": "Line in the older revision, empty if added",
+                    "type": "string",
+                    "example": "You set my soul alight"
+                },
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DiffOp"
+                        }
+                    ],
+                    "example": "changed"
+                }
+            }
+        },
+        "models.LyricsMatch": {
+            "type": "object",
+            "required": [
+                "group",
+                "link",
+                "name",
+                "releaseDate",
+                "text"
+            ],
+            "properties": {
+                "group": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "groupID": {
+                    "description": "Group ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "headline": {
+                    "description": "Matching verses with found words wrapped in \u003cb\u003e\u003c/b\u003e",
+                    "type": "string",
+                    "example": "You set my soul \u003cb\u003ealight\u003c/b\u003e"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "link": {
+                    "description": "URL link",
+                    "type": "string",
+                    "example": "http://example.org"
+                },
+                "name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "rank": {
+                    "description": "Relevance, higher is better",
+                    "type": "number",
+                    "example": 0.6079271
+                },
+                "releaseDate": {
+                    "description": "Release date in 02.01.2006 format",
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "text": {
+                    "description": "Song text",
+                    "type": "string",
+                    "example": "Some text\n"
+                },
+                "version": {
+                    "description": "Revision number, incremented on every change",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.LyricsPage": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Section"
+                    }
+                },
+                "page": {
+                    "description": "Page number from 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "per_page": {
+                    "description": "Sections per page",
+                    "type": "integer",
+                    "example": 2
+                },
+                "selected": {
+                    "description": "Number of sections in the range on all pages, repeats in a row are\ncounted once in compact rendering",
+                    "type": "integer",
+                    "example": 3
+                },
+                "total": {
+                    "description": "Number of sections of the song",
+                    "type": "integer",
+                    "example": 6
+                }
+            }
+        },
+        "models.PatchParams": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "description": "Group ID, takes precedence over the group name",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "group_name": {
+                    "description": "Group name",
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 1,
+                    "example": "Muse"
+                },
+                "link": {
+                    "description": "URL link",
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "http://example.org"
+                },
+                "release_date": {
+                    "description": "Release date in 02.01.2006 format",
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "16.07.2006"
+                },
+                "song_name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1,
+                    "example": "Supermassive Black Hole"
+                },
+                "song_text": {
+                    "description": "Song text",
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Some text\n\n Some text2\n"
+                }
+            }
+        },
+        "models.Revision": {
+            "type": "object",
+            "required": [
+                "group",
+                "link",
+                "name",
+                "releaseDate",
+                "text"
+            ],
+            "properties": {
+                "action": {
+                    "description": "Kind of change",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RevisionAction"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "author": {
+                    "description": "Who made the change, empty if unknown",
+                    "type": "string",
+                    "example": "editor"
+                },
+                "createdAt": {
+                    "description": "Time of the change",
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "group": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "groupID": {
+                    "description": "Group ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "link": {
+                    "description": "URL link",
+                    "type": "string",
+                    "example": "http://example.org"
+                },
+                "name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "releaseDate": {
+                    "description": "Release date in 02.01.2006 format",
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "text": {
+                    "description": "Song text",
+                    "type": "string",
+                    "example": "Some text\n"
+                },
+                "version": {
+                    "description": "Revision number, incremented on every change",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.RevisionAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "restore",
+                "revert"
+            ],
+            "x-enum-varnames": [
+                "RevisionCreate",
+                "RevisionUpdate",
+                "RevisionDelete",
+                "RevisionRestore",
+                "RevisionRevert"
+            ]
+        },
+        "models.SearchPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "page": {
+                    "description": "Page number from 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "per_page": {
+                    "description": "Records per page",
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "description": "Number of songs on all pages",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.Section": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "One of verse, chorus, bridge, intro, outro",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SectionKind"
+                        }
+                    ],
+                    "example": "chorus"
+                },
+                "num": {
+                    "description": "Section number, starting from 1",
+                    "type": "integer",
+                    "example": 2
+                },
+                "ref": {
+                    "description": "Number of the earlier section this one repeats, 0 if none",
+                    "type": "integer",
+                    "example": 0
+                },
+                "repeat": {
+                    "description": "Times the section is sung in a row, more than 1 only in compact rendering",
+                    "type": "integer",
+                    "example": 1
+                },
+                "text": {
+                    "description": "Section lines without the annotation",
+                    "type": "string",
+                    "example": "Oh baby don't you know I suffer?"
+                }
+            }
+        },
+        "models.SectionKind": {
+            "type": "string",
+            "enum": [
+                "verse",
+                "chorus",
+                "bridge",
+                "intro",
+                "outro"
+            ],
+            "x-enum-varnames": [
+                "SectionVerse",
+                "SectionChorus",
+                "SectionBridge",
+                "SectionIntro",
+                "SectionOutro"
+            ]
+        },
+        "models.Song": {
+            "type": "object",
+            "required": [
+                "group",
+                "link",
+                "name",
+                "releaseDate",
+                "text"
+            ],
+            "properties": {
+                "group": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "groupID": {
+                    "description": "Group ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "link": {
+                    "description": "URL link",
+                    "type": "string",
+                    "example": "http://example.org"
+                },
+                "name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "releaseDate": {
+                    "description": "Release date in 02.01.2006 format",
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "text": {
+                    "description": "Song text",
+                    "type": "string",
+                    "example": "Some text\n"
+                },
+                "version": {
+                    "description": "Revision number, incremented on every change",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.SongDetails": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "group": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "group_id": {
+                    "description": "Group ID, optional: picks one of several groups sharing the name",
+                    "type": "integer",
+                    "example": 1
+                },
+                "song": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                }
+            }
+        },
+        "models.SongDiff": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "Older revision number",
+                    "type": "integer",
+                    "example": 3
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
+                    "description": "Newer revision number",
+                    "type": "integer",
+                    "example": 5
+                },
+                "verses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.VerseDiff"
+                    }
+                }
+            }
+        },
+        "models.SongsPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Opaque cursor of the next page, empty on the last page",
+                    "type": "string",
+                    "example": "eyJzIjoiZ3JvdXBfbmFtZSxpZCIsInYiOlsiTXVzZSIsMV19"
+                }
+            }
+        },
+        "models.Suggestion": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "groupID": {
+                    "description": "Group ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "score": {
+                    "description": "Similarity from 0 to 1",
+                    "type": "number",
+                    "example": 0.85
+                }
+            }
+        },
+        "models.TrashedSong": {
+            "type": "object",
+            "required": [
+                "group",
+                "link",
+                "name",
+                "releaseDate",
+                "text"
+            ],
+            "properties": {
+                "deletedAt": {
+                    "description": "Deletion time",
+                    "type": "string",
+                    "example": "2024-05-01T12:00:00Z"
+                },
+                "group": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "groupID": {
+                    "description": "Group ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "link": {
+                    "description": "URL link",
+                    "type": "string",
+                    "example": "http://example.org"
+                },
+                "name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "releaseDate": {
+                    "description": "Release date in 02.01.2006 format",
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "text": {
+                    "description": "Song text",
+                    "type": "string",
+                    "example": "Some text\n"
+                },
+                "version": {
+                    "description": "Revision number, incremented on every change",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.UpdateParams": {
+            "type": "object",
+            "required": [
+                "group_name",
+                "link",
+                "release_date",
+                "song_name",
+                "song_text"
+            ],
+            "properties": {
+                "group_id": {
+                    "description": "Group ID, optional: takes precedence over the group name",
+                    "type": "integer",
+                    "example": 1
+                },
+                "group_name": {
+                    "description": "Group name",
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "link": {
+                    "description": "URL link",
+                    "type": "string",
+                    "example": "http://example.org"
+                },
+                "release_date": {
+                    "description": "Release date in 02.01.2006 format",
+                    "type": "string",
+                    "example": "16.07.2006"
+                },
+                "song_name": {
+                    "description": "Song name",
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "song_text": {
+                    "description": "Song text",
+                    "type": "string",
+                    "example": "Some text\n\n Some text2\n"
+                }
+            }
+        },
+        "models.Verse": {
+            "type": "object",
+            "properties": {
+                "num": {
+                    "description": "verse number",
+                    "type": "string",
+                    "example": "1"
+                },
+                "text": {
+                    "description": "verse text",
+                    "type": "string",
+                    "example": "Some text\n"
+                }
+            }
+        },
+        "models.VerseDiff": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "Verse number from 1 in the older revision, 0 if added",
+                    "type": "integer",
+                    "example": 2
+                },
+                "lines": {
+                    "description": "All lines of the verse, changed verses keep equal lines for context",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LineDiff"
+                    }
+                },
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DiffOp"
+                        }
+                    ],
+                    "example": "changed"
+                },
+                "to": {
+                    "description": "Verse number from 1 in the newer revision, 0 if removed",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "rest.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error string",
+                    "type": "string",
+                    "example": "error description"
+                },
+                "existing_id": {
+                    "description": "ID of the existing record on conflict",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         }
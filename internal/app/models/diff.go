@@ -0,0 +1,42 @@
+package models
+
+// DiffOp tells how a verse or a line differs between song revisions.
+type DiffOp string
+
+const (
+	DiffEqual   DiffOp = "equal"
+	DiffAdded   DiffOp = "added"
+	DiffRemoved DiffOp = "removed"
+	DiffChanged DiffOp = "changed"
+)
+
+// LineDiff is a line of a verse in two revisions.
+type LineDiff struct {
+	Op DiffOp `example:"changed"`
+	// Line in the older revision, empty if added
+	Old string `example:"You set my soul alight"`
+	// Line in the newer revision, empty if removed
+	New string `example:"You set my soul on fire"`
+}
+
+// VerseDiff is a verse added, removed or changed between song revisions.
+type VerseDiff struct {
+	Op DiffOp `example:"changed"`
+	// Verse number from 1 in the older revision, 0 if added
+	From int `example:"2"`
+	// Verse number from 1 in the newer revision, 0 if removed
+	To int `example:"2"`
+	// All lines of the verse, changed verses keep equal lines for context
+	Lines []LineDiff
+}
+
+// SongDiff lists lyrics verses that differ between two song revisions,
+// unchanged verses are left out.
+type SongDiff struct {
+	ID int32 `example:"1"`
+	// Older revision number
+	From int32 `example:"3"`
+	// Newer revision number
+	To     int32 `example:"5"`
+	Verses []VerseDiff
+}
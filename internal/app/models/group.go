@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+type Group struct {
+	ID int32 `example:"1"`
+	// Group name
+	Name string `validate:"required" example:"Muse"`
+}
+
+func (g *Group) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(g); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package models
+
+// Operator is a comparison used by a search criterion.
+type Operator string
+
+const (
+	// OpEq is an exact match, the default operator.
+	OpEq Operator = "eq"
+	// OpIEq is a case-insensitive exact match.
+	OpIEq Operator = "ieq"
+	// OpPrefix matches values starting with the given text.
+	OpPrefix Operator = "prefix"
+	// OpContains matches values containing the given text.
+	OpContains Operator = "contains"
+	// OpILike matches values containing the given text, case-insensitive.
+	OpILike Operator = "ilike"
+	OpGt    Operator = "gt"
+	OpGte   Operator = "gte"
+	OpLt    Operator = "lt"
+	OpLte   Operator = "lte"
+	// OpIn matches any of several values.
+	OpIn Operator = "in"
+	// OpSimilar is a typo-tolerant trigram match.
+	OpSimilar Operator = "similar"
+)
+
+// Criterion is a single search condition on a song field, e.g.
+// release_date[gte]=01.01.2005. Values hold typed field values: int32 for
+// group_id, time.Time for release_date and string otherwise. OpIn takes
+// one or more values, other operators take exactly one.
+type Criterion struct {
+	Field  string
+	Op     Operator
+	Values []any
+}
+
+// SortKey orders search results by a song field.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SearchQuery selects songs matching all criteria in the given order.
+// After is a keyset position: values of OrderKeys fields, only songs
+// following it are selected.
+type SearchQuery struct {
+	Criteria []Criterion
+	Sort     []SortKey
+	After    []any
+}
+
+// OrderKeys returns the effective order: Sort, by group name if empty,
+// tie-broken by id.
+func (q SearchQuery) OrderKeys() []SortKey {
+	keys := q.Sort
+	if len(keys) == 0 {
+		keys = []SortKey{{Field: "group_name"}}
+	}
+
+	for _, k := range keys {
+		if k.Field == "id" {
+			return keys
+		}
+	}
+
+	return append(keys[:len(keys):len(keys)], SortKey{Field: "id"})
+}
+
+// SongsPage is a page of songs listed with keyset pagination.
+type SongsPage struct {
+	Items []Song `json:"items"`
+	// Opaque cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiZ3JvdXBfbmFtZSxpZCIsInYiOlsiTXVzZSIsMV19"`
+}
+
+// SearchPage is a page of songs listed by page number.
+type SearchPage struct {
+	Items []Song `json:"items"`
+	// Number of songs on all pages
+	Total int `json:"total" example:"42"`
+	// Page number from 0
+	Page int `json:"page" example:"0"`
+	// Records per page
+	PerPage int `json:"per_page" example:"10"`
+}
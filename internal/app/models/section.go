@@ -0,0 +1,144 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SectionKind tells the role of a lyrics section.
+type SectionKind string
+
+const (
+	SectionVerse  SectionKind = "verse"
+	SectionChorus SectionKind = "chorus"
+	SectionBridge SectionKind = "bridge"
+	SectionIntro  SectionKind = "intro"
+	SectionOutro  SectionKind = "outro"
+)
+
+// Section is a part of song lyrics separated by blank lines.
+type Section struct {
+	// Section number, starting from 1
+	Num int `example:"2"`
+	// One of verse, chorus, bridge, intro, outro
+	Kind SectionKind `example:"chorus"`
+	// Section lines without the annotation
+	Text string `example:"Oh baby don't you know I suffer?"`
+	// Number of the earlier section this one repeats, 0 if none
+	Ref int `example:"0"`
+	// Times the section is sung in a row, more than 1 only in compact rendering
+	Repeat int `example:"1"`
+}
+
+// LyricsPage is a page of song sections selected by a range of numbers.
+type LyricsPage struct {
+	ID    int32     `json:"id" example:"1"`
+	Items []Section `json:"items"`
+	// Number of sections of the song
+	Total int `json:"total" example:"6"`
+	// Number of sections in the range on all pages, repeats in a row are
+	// counted once in compact rendering
+	Selected int `json:"selected" example:"3"`
+	// Page number from 0
+	Page int `json:"page" example:"0"`
+	// Sections per page
+	PerPage int `json:"per_page" example:"2"`
+}
+
+// sectionHeader matches an annotation line like [Chorus] or [Verse 2: Bellamy].
+var sectionHeader = regexp.MustCompile(`(?i)^\[\s*(verse|chorus|bridge|intro|outro)\b[^\]]*\]$`)
+
+// ParseSections splits lyrics into sections by blank lines. A section is
+// typed by an annotation line preceding it, sections without one are
+// verses. An annotation inside a block starts a new section, one alone in
+// a block, like [Chorus x2] standing for a repeat, is kept as a verse text.
+// CRLF and CR line breaks are accepted, blank lyrics have no sections.
+func ParseSections(text string) []Section {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var (
+		sections []Section
+		lines    []string
+		kind     = SectionVerse
+		header   string
+	)
+	flush := func() {
+		if len(lines) == 0 && header != "" {
+			kind, lines = SectionVerse, []string{header}
+		}
+		header = ""
+		if len(lines) == 0 {
+			return
+		}
+		sections = append(sections, Section{
+			Num:    len(sections) + 1,
+			Kind:   kind,
+			Text:   strings.Join(lines, "\n"),
+			Repeat: 1,
+		})
+		lines = nil
+		kind = SectionVerse
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if m := sectionHeader.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			kind, header = SectionKind(strings.ToLower(m[1])), trimmed
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return sections
+}
+
+// Title is the kind name as written in annotations.
+func (k SectionKind) Title() string {
+	if k == "" {
+		return ""
+	}
+
+	return strings.ToUpper(string(k[:1])) + string(k[1:])
+}
+
+// Label is the annotation of the section, empty for verses.
+func (s Section) Label() string {
+	if s.Kind == SectionVerse || s.Kind == "" {
+		return ""
+	}
+
+	return "[" + s.Kind.Title() + "]"
+}
+
+// String is the section text preceded by its annotation line.
+func (s Section) String() string {
+	if label := s.Label(); label != "" {
+		return label + "\n" + s.Text
+	}
+
+	return s.Text
+}
+
+// FormatSections joins sections back into lyrics, the result parses into
+// the same sections.
+func FormatSections(sections []Section) string {
+	parts := make([]string, len(sections))
+	for i, s := range sections {
+		parts[i] = s.String()
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// NormalizeLyrics rewrites lyrics in the form they are stored: LF line
+// breaks, sections separated by a single blank line, canonical annotations.
+func NormalizeLyrics(text string) string {
+	return FormatSections(ParseSections(text))
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	verse := func(num int, kind SectionKind, text string) Section {
+		return Section{Num: num, Kind: kind, Text: text, Repeat: 1}
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []Section
+		// normalized text, the same as text when empty
+		norm string
+	}{
+		{
+			name: "blank",
+			text: " \n\n\t",
+			want: nil,
+		},
+		{
+			name: "verses",
+			text: "a\nb\n\nc",
+			want: []Section{verse(1, SectionVerse, "a\nb"), verse(2, SectionVerse, "c")},
+		},
+		{
+			name: "annotated",
+			text: "[Chorus]\na\n\nb",
+			want: []Section{verse(1, SectionChorus, "a"), verse(2, SectionVerse, "b")},
+		},
+		{
+			name: "annotation inside a block",
+			text: "a\n[Verse 2: Bellamy]\nb",
+			want: []Section{verse(1, SectionVerse, "a"), verse(2, SectionVerse, "b")},
+			norm: "a\n\nb",
+		},
+		{
+			name: "header only",
+			text: "[Chorus]\n\na",
+			want: []Section{verse(1, SectionVerse, "[Chorus]"), verse(2, SectionVerse, "a")},
+		},
+		{
+			name: "header only at the end",
+			text: "a\n\n[chorus x2]",
+			want: []Section{verse(1, SectionVerse, "a"), verse(2, SectionVerse, "[chorus x2]")},
+		},
+		{
+			name: "header before header",
+			text: "[Intro]\n[Chorus]\na",
+			want: []Section{verse(1, SectionVerse, "[Intro]"), verse(2, SectionChorus, "a")},
+			norm: "[Intro]\n\n[Chorus]\na",
+		},
+		{
+			name: "repeat count",
+			text: "[Chorus x3]\na\n\n[Bridge]\nb",
+			want: []Section{verse(1, SectionChorus, "a"), verse(2, SectionBridge, "b")},
+			norm: "[Chorus]\na\n\n[Bridge]\nb",
+		},
+		{
+			name: "crlf",
+			text: "[Intro]\r\na\r\n\r\n\r\nb\r\nc\r\n",
+			want: []Section{verse(1, SectionIntro, "a"), verse(2, SectionVerse, "b\nc")},
+			norm: "[Intro]\na\n\nb\nc",
+		},
+		{
+			name: "crlf header only",
+			text: "[Chorus x2]\r\n\r\na\r",
+			want: []Section{verse(1, SectionVerse, "[Chorus x2]"), verse(2, SectionVerse, "a")},
+			norm: "[Chorus x2]\n\na",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSections(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("sections %+v, want %+v", got, tt.want)
+			}
+
+			norm := tt.norm
+			if norm == "" && tt.want != nil {
+				norm = tt.text
+			}
+			if got := NormalizeLyrics(tt.text); got != norm {
+				t.Fatalf("normalized %q, want %q", got, norm)
+			}
+			if got := NormalizeLyrics(norm); got != norm {
+				t.Fatalf("normalized twice %q, want %q", got, norm)
+			}
+		})
+	}
+}
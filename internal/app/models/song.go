@@ -8,6 +8,8 @@ import (
 
 type Song struct {
 	ID int32 `example:"1"`
+	// Group ID
+	GroupID int32 `example:"1"`
 	// Group name
 	Group string `validate:"required" example:"Muse"`
 	// Song name
@@ -18,6 +20,8 @@ type Song struct {
 	Text string `validate:"required" example:"Some text\n"`
 	// URL link
 	Link string `validate:"required" example:"http://example.org"`
+	// Revision number, incremented on every change
+	Version int32 `example:"1"`
 }
 
 func (s *Song) Validate() error {
@@ -28,3 +32,55 @@ func (s *Song) Validate() error {
 
 	return nil
 }
+
+// TrashedSong is a deleted song kept in the trash.
+type TrashedSong struct {
+	Song
+	// Deletion time
+	DeletedAt time.Time `example:"2024-05-01T12:00:00Z"`
+}
+
+// LyricsMatch is a song found by a full-text lyrics search.
+type LyricsMatch struct {
+	Song
+	// Relevance, higher is better
+	Rank float32 `example:"0.6079271"`
+	// Matching verses with found words wrapped in <b></b>
+	Headline string `example:"You set my soul <b>alight</b>"`
+}
+
+// Suggestion is a song title similar to an autocomplete query.
+type Suggestion struct {
+	ID int32 `example:"1"`
+	// Group ID
+	GroupID int32 `example:"1"`
+	// Group name
+	Group string `example:"Muse"`
+	// Song name
+	Name string `example:"Supermassive Black Hole"`
+	// Similarity from 0 to 1
+	Score float32 `example:"0.85"`
+}
+
+// RevisionAction is the kind of change that made a song revision.
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+	RevisionRevert  RevisionAction = "revert"
+)
+
+// Revision is the state of a song after a change, its Version is the
+// revision number.
+type Revision struct {
+	Song
+	// Kind of change
+	Action RevisionAction `example:"update"`
+	// Who made the change, empty if unknown
+	Author string `example:"editor"`
+	// Time of the change
+	CreatedAt time.Time `example:"2024-05-01T12:00:00Z"`
+}
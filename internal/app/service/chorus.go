@@ -0,0 +1,175 @@
+package service
+
+import (
+	"music/internal/app/models"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// chorusSimilarity is the least share of common normalized lines for
+	// two sections to be taken as repeats of each other.
+	chorusSimilarity = 0.8
+	// lineSimilarity is the least share of characters two normalized lines
+	// keep in common by edit distance to be taken as the same line.
+	lineSimilarity = 0.85
+)
+
+// markChoruses links every section repeating an earlier one to it by Ref.
+// Repeated unannotated sections are choruses, a repeat takes the kind of
+// the section it repeats.
+func markChoruses(sections []models.Section) {
+	lines := make([][]string, len(sections))
+	for i, sec := range sections {
+		lines[i] = normalizeLines(sec.Text)
+	}
+
+	repeated := make([]bool, len(sections))
+	for i := range sections {
+		for j := 0; j < i; j++ {
+			if sections[j].Ref != 0 || !similarLines(lines[j], lines[i]) {
+				continue
+			}
+			sections[i].Ref = sections[j].Num
+			repeated[i], repeated[j] = true, true
+			break
+		}
+	}
+
+	for i := range sections {
+		if repeated[i] && sections[i].Ref == 0 && sections[i].Kind == models.SectionVerse {
+			sections[i].Kind = models.SectionChorus
+		}
+	}
+	for i := range sections {
+		if sections[i].Ref != 0 && sections[i].Kind == models.SectionVerse {
+			sections[i].Kind = sections[sections[i].Ref-1].Kind
+		}
+	}
+}
+
+// normalizeLines splits text into lines of lower case words, punctuation
+// and spacing are dropped along with lines left empty.
+func normalizeLines(text string) []string {
+	var lines []string
+	for _, line := range splitLines(text) {
+		words := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) > 0 {
+			lines = append(lines, strings.Join(words, " "))
+		}
+	}
+
+	return lines
+}
+
+// similarLines tells whether lines of two sections are mostly the same
+// and in the same order.
+func similarLines(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+
+	// lcs[i][j] is the longest common subsequence length of a[i:] and b[j:]
+	// with similar lines taken as equal
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if similarLine(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	return float64(2*lcs[0][0]) >= chorusSimilarity*float64(len(a)+len(b))
+}
+
+// similarLine tells whether normalized lines differ by a few characters.
+func similarLine(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+
+	return float64(longest-editDistance(ra, rb)) >= lineSimilarity*float64(longest)
+}
+
+// editDistance is the Levenshtein distance between rune strings.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		cur[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j]+cost, prev[j+1]+1, cur[j]+1)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+// compactSections merges repeats in a row into one section counting them
+// in Repeat. A section repeating one already shown is replaced by a marker
+// like [Chorus x3], the first occurrence keeps its text.
+func compactSections(sections []models.Section) []models.Section {
+	compact := make([]models.Section, 0, len(sections))
+	keys := make([]int, 0, len(sections))
+	markers := make([]bool, 0, len(sections))
+	shown := make(map[int]bool)
+
+	for _, sec := range sections {
+		key := sec.Num
+		if sec.Ref != 0 {
+			key = sec.Ref
+		}
+
+		if n := len(compact); n > 0 && keys[n-1] == key {
+			compact[n-1].Repeat++
+			continue
+		}
+
+		compact = append(compact, sec)
+		keys = append(keys, key)
+		markers = append(markers, shown[key])
+		shown[key] = true
+	}
+
+	for i := range compact {
+		if markers[i] {
+			compact[i].Text = repeatMarker(compact[i])
+		}
+	}
+
+	return compact
+}
+
+// repeatMarker renders a reference to the repeated section like [Chorus]
+// or [Chorus x3].
+func repeatMarker(sec models.Section) string {
+	title := sec.Kind.Title()
+	if title == "" {
+		title = models.SectionVerse.Title()
+	}
+	if sec.Repeat > 1 {
+		title += " x" + strconv.Itoa(sec.Repeat)
+	}
+
+	return "[" + title + "]"
+}
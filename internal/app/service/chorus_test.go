@@ -0,0 +1,203 @@
+package service
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"music/internal/app/models"
+)
+
+func TestSimilarLine(t *testing.T) {
+	// 20 characters, lines keeping 17 of them are similar
+	line := "abcdefghijklmnopqrst"
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"equal", line, line, true},
+		{"three edits", line, "abcXefghiXklmnoXqrst", true},
+		{"four edits", line, "abcXefghiXklmnoXqrsX", false},
+		{"three missing", line, "abcefghijlmnopqst", true},
+		{"four missing", line, "abcefghijlmnopqs", false},
+		{"different", "you set my soul alight", "supermassive black hole", false},
+		{"empty", line, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarLine(tt.a, tt.b); got != tt.want {
+				t.Fatalf("similar %v, want %v", got, tt.want)
+			}
+			if got := similarLine(tt.b, tt.a); got != tt.want {
+				t.Fatalf("reversed similar %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarLines(t *testing.T) {
+	five := []string{"one line", "two lines", "three lines", "four lines", "five lines"}
+
+	tests := []struct {
+		name string
+		a, b []string
+		want bool
+	}{
+		{"exact", five, five, true},
+		{"one line of five differs", five, []string{"one line", "two lines", "something else", "four lines", "five lines"}, true},
+		{"two lines of five differ", five, []string{"one line", "something else", "three lines", "another thing", "five lines"}, false},
+		{"one line of five missing", five, five[:4], true},
+		{"two lines of five missing", five, five[:3], false},
+		{"near lines", five, []string{"one line", "two lines", "tree lines", "four line", "five lines"}, true},
+		{"reordered", five, []string{"five lines", "four lines", "three lines", "two lines", "one line"}, false},
+		{"empty", five, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarLines(tt.a, tt.b); got != tt.want {
+				t.Fatalf("similar %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// sectionKinds renders sections as kind and ref pairs like chorus:2.
+func sectionKinds(sections []models.Section) []string {
+	kinds := make([]string, len(sections))
+	for i, sec := range sections {
+		kinds[i] = string(sec.Kind)
+		if sec.Ref != 0 {
+			kinds[i] += ":" + strconv.Itoa(sec.Ref)
+		}
+	}
+
+	return kinds
+}
+
+const (
+	verse1 = "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"
+	verse2 = "I thought I was a fool for no one\nOh baby, I'm a fool for you"
+	chorus = "Ooh\nYou set my soul alight\nOoh\nYou set my soul alight"
+	bridge = "Glaciers melting in the dead of night\nAnd the superstars sucked into the supermassive"
+)
+
+func TestMarkChoruses(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "no repeats",
+			text: verse1 + "\n\n" + verse2 + "\n\n" + bridge,
+			want: []string{"verse", "verse", "verse"},
+		},
+		{
+			name: "exact repeats",
+			text: verse1 + "\n\n" + chorus + "\n\n" + verse2 + "\n\n" + chorus,
+			want: []string{"verse", "chorus", "verse", "chorus:2"},
+		},
+		{
+			name: "near duplicate",
+			text: verse1 + "\n\n" + chorus + "\n\n" + verse2 + "\n\n" +
+				"OOH!\nYou set my soul a light\nOoh\nYou set my sould alight!",
+			want: []string{"verse", "chorus", "verse", "chorus:2"},
+		},
+		{
+			name: "one line of five differs",
+			text: chorus + "\nOoh\n\n" + chorus + "\nYeah",
+			want: []string{"chorus", "chorus:1"},
+		},
+		{
+			name: "two lines of five differ",
+			text: chorus + "\nOoh\n\n" + strings.Replace(chorus, "Ooh", "Yeah", 1) + "\nYeah",
+			want: []string{"verse", "verse"},
+		},
+		{
+			name: "annotated repeat keeps the kind",
+			text: "[Bridge]\n" + bridge + "\n\n" + verse1 + "\n\n" + bridge,
+			want: []string{"bridge", "verse", "bridge:1"},
+		},
+		{
+			name: "repeats link to the first occurrence",
+			text: chorus + "\n\n" + chorus + "\n\n" + chorus,
+			want: []string{"chorus", "chorus:1", "chorus:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := models.ParseSections(tt.text)
+			markChoruses(sections)
+			if got := sectionKinds(sections); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("sections %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactSections(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		// texts of compact sections and their repeat counts
+		want    []string
+		repeats []int
+	}{
+		{
+			name:    "no repeats",
+			text:    verse1 + "\n\n" + chorus + "\n\n" + verse2,
+			want:    []string{verse1, chorus, verse2},
+			repeats: []int{1, 1, 1},
+		},
+		{
+			name:    "first run keeps the text",
+			text:    verse1 + "\n\n" + chorus + "\n\n" + chorus + "\n\n" + chorus,
+			want:    []string{verse1, chorus},
+			repeats: []int{1, 3},
+		},
+		{
+			name:    "later run becomes a marker",
+			text:    chorus + "\n\n" + verse1 + "\n\n" + chorus + "\n\n" + chorus,
+			want:    []string{chorus, verse1, "[Chorus x2]"},
+			repeats: []int{1, 1, 2},
+		},
+		{
+			name:    "single repeat marker",
+			text:    chorus + "\n\n" + verse1 + "\n\n" + chorus + "\n\n" + verse2,
+			want:    []string{chorus, verse1, "[Chorus]", verse2},
+			repeats: []int{1, 1, 1, 1},
+		},
+		{
+			name:    "annotated run",
+			text:    verse1 + "\n\n[Bridge]\n" + bridge + "\n\n" + verse2 + "\n\n" + bridge + "\n\n" + bridge + "\n\n" + bridge,
+			want:    []string{verse1, bridge, verse2, "[Bridge x3]"},
+			repeats: []int{1, 1, 1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := models.ParseSections(tt.text)
+			markChoruses(sections)
+			compact := compactSections(sections)
+
+			texts := make([]string, len(compact))
+			repeats := make([]int, len(compact))
+			for i, sec := range compact {
+				texts[i], repeats[i] = sec.Text, sec.Repeat
+			}
+			if !reflect.DeepEqual(texts, tt.want) {
+				t.Fatalf("texts %q, want %q", texts, tt.want)
+			}
+			if !reflect.DeepEqual(repeats, tt.repeats) {
+				t.Fatalf("repeats %v, want %v", repeats, tt.repeats)
+			}
+		})
+	}
+}
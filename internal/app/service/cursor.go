@@ -0,0 +1,120 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"music/internal/app/models"
+)
+
+// cursorDate is the release date layout inside cursors.
+const cursorDate = "2006-01-02"
+
+// cursor is the decoded keyset position of a song listing. It keeps the
+// order it was made for, so it is not applied to a differently sorted listing.
+type cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// sortSpec renders order keys in the sort param format.
+func sortSpec(keys []models.SortKey) string {
+	fields := make([]string, len(keys))
+	for i, k := range keys {
+		fields[i] = k.Field
+		if k.Desc {
+			fields[i] = "-" + k.Field
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// encodeCursor makes an opaque cursor pointing right after the song.
+func encodeCursor(keys []models.SortKey, last models.Song) (string, error) {
+	c := cursor{Sort: sortSpec(keys), Values: make([]json.RawMessage, len(keys))}
+	for i, k := range keys {
+		var v any
+		switch k.Field {
+		case "id":
+			v = last.ID
+		case "group_id":
+			v = last.GroupID
+		case "group_name":
+			v = last.Group
+		case "song_name":
+			v = last.Name
+		case "release_date":
+			v = last.ReleaseDate.Format(cursorDate)
+		case "link":
+			v = last.Link
+		default:
+			return "", fmt.Errorf("can not sort by %q", k.Field)
+		}
+
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = raw
+	}
+
+	content, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+// decodeCursor returns the order key values stored in the cursor, it must
+// have been made for the same order.
+func decodeCursor(keys []models.SortKey, s string) ([]any, error) {
+	content, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", err)
+	}
+
+	var c cursor
+	if err := json.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", err)
+	}
+	if c.Sort != sortSpec(keys) || len(c.Values) != len(keys) {
+		return nil, fmt.Errorf("cursor does not match sort order %q", sortSpec(keys))
+	}
+
+	vals := make([]any, len(keys))
+	for i, k := range keys {
+		switch k.Field {
+		case "id", "group_id":
+			var v int32
+			if err := json.Unmarshal(c.Values[i], &v); err != nil {
+				return nil, fmt.Errorf("malformed cursor: %w", err)
+			}
+			vals[i] = v
+
+		case "release_date":
+			var v string
+			if err := json.Unmarshal(c.Values[i], &v); err != nil {
+				return nil, fmt.Errorf("malformed cursor: %w", err)
+			}
+			t, err := time.Parse(cursorDate, v)
+			if err != nil {
+				return nil, fmt.Errorf("malformed cursor: %w", err)
+			}
+			vals[i] = t
+
+		default:
+			var v string
+			if err := json.Unmarshal(c.Values[i], &v); err != nil {
+				return nil, fmt.Errorf("malformed cursor: %w", err)
+			}
+			vals[i] = v
+		}
+	}
+
+	return vals, nil
+}
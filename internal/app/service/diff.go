@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"music/internal/app/models"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around changes in unified
+// diff hunks.
+const diffContext = 3
+
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+// edit is a step of an edit script, a and b are positions in both sequences
+// before the step.
+type edit struct {
+	kind editKind
+	a, b int
+}
+
+// diffSeq returns the shortest edit script turning a into b, in a run of
+// changes deletions go first.
+func diffSeq(a, b []string) []edit {
+	n, m := len(a), len(b)
+
+	// lcs[i][j] is the longest common subsequence length of a[i:] and b[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			edits = append(edits, edit{kind: editEqual, a: i, b: j})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{kind: editDelete, a: i, b: j})
+			i++
+		default:
+			edits = append(edits, edit{kind: editInsert, a: i, b: j})
+			j++
+		}
+	}
+
+	return edits
+}
+
+// walkEdits calls equal for elements kept and change for every run of
+// deleted and inserted elements between them.
+func walkEdits(edits []edit, equal func(a, b int), change func(del, ins []int)) {
+	var del, ins []int
+	flush := func() {
+		if len(del) > 0 || len(ins) > 0 {
+			change(del, ins)
+		}
+		del, ins = nil, nil
+	}
+
+	for _, e := range edits {
+		switch e.kind {
+		case editEqual:
+			flush()
+			equal(e.a, e.b)
+		case editDelete:
+			del = append(del, e.a)
+		case editInsert:
+			ins = append(ins, e.b)
+		}
+	}
+	flush()
+}
+
+// diffVerses compares lyrics verse by verse. Deleted and inserted verses
+// between the same unchanged ones are paired in order as changed verses,
+// the rest are removed or added.
+func diffVerses(oldText, newText string) []models.VerseDiff {
+	a, b := splitVerses(oldText), splitVerses(newText)
+	verses := make([]models.VerseDiff, 0)
+
+	walkEdits(diffSeq(a, b), func(int, int) {}, func(del, ins []int) {
+		for k := 0; k < max(len(del), len(ins)); k++ {
+			switch {
+			case k < len(del) && k < len(ins):
+				verses = append(verses, models.VerseDiff{
+					Op:    models.DiffChanged,
+					From:  del[k] + 1,
+					To:    ins[k] + 1,
+					Lines: diffLines(a[del[k]], b[ins[k]]),
+				})
+			case k < len(del):
+				verses = append(verses, models.VerseDiff{
+					Op:    models.DiffRemoved,
+					From:  del[k] + 1,
+					Lines: diffLines(a[del[k]], ""),
+				})
+			default:
+				verses = append(verses, models.VerseDiff{
+					Op:    models.DiffAdded,
+					To:    ins[k] + 1,
+					Lines: diffLines("", b[ins[k]]),
+				})
+			}
+		}
+	})
+
+	return verses
+}
+
+// diffLines compares lines of a verse, pairing deleted and inserted lines
+// like diffVerses does with verses.
+func diffLines(oldVerse, newVerse string) []models.LineDiff {
+	a, b := splitLines(oldVerse), splitLines(newVerse)
+	lines := make([]models.LineDiff, 0, max(len(a), len(b)))
+
+	walkEdits(diffSeq(a, b), func(i, j int) {
+		lines = append(lines, models.LineDiff{Op: models.DiffEqual, Old: a[i], New: b[j]})
+	}, func(del, ins []int) {
+		for k := 0; k < max(len(del), len(ins)); k++ {
+			switch {
+			case k < len(del) && k < len(ins):
+				lines = append(lines, models.LineDiff{Op: models.DiffChanged, Old: a[del[k]], New: b[ins[k]]})
+			case k < len(del):
+				lines = append(lines, models.LineDiff{Op: models.DiffRemoved, Old: a[del[k]]})
+			default:
+				lines = append(lines, models.LineDiff{Op: models.DiffAdded, New: b[ins[k]]})
+			}
+		}
+	})
+
+	return lines
+}
+
+// splitLines splits text into lines, empty text has none.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+// unifiedDiff renders the line diff of lyrics in unified format. Blank lines
+// separate verses, hunks are titled with the verse of the newer text they
+// start in.
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	a, b := splitLines(oldText), splitLines(newText)
+	edits := diffSeq(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(edits); {
+		// find the first change and the end of changes close to it: changes
+		// apart by at most two contexts of equal lines share a hunk
+		first := start
+		for first < len(edits) && edits[first].kind == editEqual {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for k := first; k < len(edits) && k-last-1 <= 2*diffContext; k++ {
+			if edits[k].kind != editEqual {
+				last = k
+			}
+		}
+
+		from := max(first-diffContext, start)
+		to := min(last+diffContext+1, len(edits))
+		hunk := edits[from:to]
+
+		var oldLines, newLines int
+		for _, e := range hunk {
+			if e.kind != editInsert {
+				oldLines++
+			}
+			if e.kind != editDelete {
+				newLines++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@ verse %d\n",
+			hunkRange(hunk[0].a, oldLines), hunkRange(hunk[0].b, newLines), verseAt(b, hunk[0].b))
+		for _, e := range hunk {
+			switch e.kind {
+			case editEqual:
+				sb.WriteString(" " + a[e.a] + "\n")
+			case editDelete:
+				sb.WriteString("-" + a[e.a] + "\n")
+			case editInsert:
+				sb.WriteString("+" + b[e.b] + "\n")
+			}
+		}
+
+		start = to
+	}
+
+	return sb.String()
+}
+
+// hunkRange renders a unified diff hunk range starting at 0-based line pos,
+// an empty range points at the line before it.
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+// verseAt returns the number of the verse line pos belongs to, a verse
+// separator belongs to the next verse.
+func verseAt(lines []string, pos int) int {
+	verse := 1
+	for _, l := range lines[:min(pos, len(lines))] {
+		if l == "" {
+			verse++
+		}
+	}
+
+	return verse
+}
+
+// revisionTexts returns lyrics of two song revisions.
+func (s *SongService) revisionTexts(ctx context.Context, id, from, to int32) (string, string, error) {
+	older, err := s.revisions.Get(ctx, id, from)
+	if err != nil {
+		return "", "", err
+	}
+
+	newer, err := s.revisions.Get(ctx, id, to)
+	if err != nil {
+		return "", "", err
+	}
+
+	return older.Text, newer.Text, nil
+}
+
+// Diff compares lyrics of two song revisions verse by verse.
+func (s *SongService) Diff(ctx context.Context, id, from, to int32) (models.SongDiff, error) {
+	oldText, newText, err := s.revisionTexts(ctx, id, from, to)
+	if err != nil {
+		return models.SongDiff{}, err
+	}
+
+	return models.SongDiff{
+		ID:     id,
+		From:   from,
+		To:     to,
+		Verses: diffVerses(oldText, newText),
+	}, nil
+}
+
+// UnifiedDiff compares lyrics of two song revisions line by line in unified
+// diff format.
+func (s *SongService) UnifiedDiff(ctx context.Context, id, from, to int32) (string, error) {
+	oldText, newText, err := s.revisionTexts(ctx, id, from, to)
+	if err != nil {
+		return "", err
+	}
+
+	return unifiedDiff(
+		fmt.Sprintf("song %d revision %d", id, from),
+		fmt.Sprintf("song %d revision %d", id, to),
+		oldText, newText,
+	), nil
+}
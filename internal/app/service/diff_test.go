@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"music/internal/app/models"
+)
+
+// numbered returns lines "01".."n" with the given lines replaced.
+func numbered(n int, replace map[int]string) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%02d", i+1)
+		if r, ok := replace[i+1]; ok {
+			lines[i] = r
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "empty to non-empty",
+			old:  "",
+			new:  "x\ny",
+			want: "@@ -0,0 +1,2 @@ verse 1\n+x\n+y\n",
+		},
+		{
+			name: "non-empty to empty",
+			old:  "x\ny",
+			new:  "",
+			want: "@@ -1,2 +0,0 @@ verse 1\n-x\n-y\n",
+		},
+		{
+			name: "identical",
+			old:  "x\n\ny",
+			new:  "x\n\ny",
+			want: "",
+		},
+		{
+			name: "close changes merge",
+			old:  numbered(10, nil),
+			new:  numbered(10, map[int]string{2: "two", 6: "six"}),
+			want: "@@ -1,9 +1,9 @@ verse 1\n" +
+				" 01\n-02\n+two\n 03\n 04\n 05\n-06\n+six\n 07\n 08\n 09\n",
+		},
+		{
+			name: "changes with adjacent contexts merge",
+			old:  numbered(12, nil),
+			new:  numbered(12, map[int]string{2: "two", 9: "nine"}),
+			want: "@@ -1,12 +1,12 @@ verse 1\n" +
+				" 01\n-02\n+two\n 03\n 04\n 05\n 06\n 07\n 08\n-09\n+nine\n 10\n 11\n 12\n",
+		},
+		{
+			name: "distant changes split",
+			old:  numbered(20, nil),
+			new:  numbered(20, map[int]string{2: "two", 15: "fifteen"}),
+			want: "@@ -1,5 +1,5 @@ verse 1\n" +
+				" 01\n-02\n+two\n 03\n 04\n 05\n" +
+				"@@ -12,7 +12,7 @@ verse 1\n" +
+				" 12\n 13\n 14\n-15\n+fifteen\n 16\n 17\n 18\n",
+		},
+		{
+			name: "insertion only",
+			old:  "a\nb",
+			new:  "a\nnew\nb",
+			want: "@@ -1,2 +1,3 @@ verse 1\n a\n+new\n b\n",
+		},
+		{
+			name: "hunk titled with the verse it starts in",
+			old:  "a\n\nb1\nb2\nb3\nb4\nb5",
+			new:  "a\n\nb1\nb2\nb3\nb4\nB5",
+			want: "@@ -4,4 +4,4 @@ verse 2\n b2\n b3\n b4\n-b5\n+B5\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := "--- old\n+++ new\n" + tt.want
+			if got := unifiedDiff("old", "new", tt.old, tt.new); got != want {
+				t.Fatalf("diff\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiffVerses(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []models.VerseDiff
+	}{
+		{
+			name: "identical",
+			old:  "a\n\nb",
+			new:  "a\n\nb",
+			want: []models.VerseDiff{},
+		},
+		{
+			name: "empty to non-empty",
+			old:  "",
+			new:  "x\ny",
+			want: []models.VerseDiff{
+				{Op: models.DiffAdded, To: 1, Lines: []models.LineDiff{
+					{Op: models.DiffAdded, New: "x"},
+					{Op: models.DiffAdded, New: "y"},
+				}},
+			},
+		},
+		{
+			name: "changed and added",
+			old:  "a\n\nb\nc",
+			new:  "a\n\nB\nc\n\nd",
+			want: []models.VerseDiff{
+				{Op: models.DiffChanged, From: 2, To: 2, Lines: []models.LineDiff{
+					{Op: models.DiffChanged, Old: "b", New: "B"},
+					{Op: models.DiffEqual, Old: "c", New: "c"},
+				}},
+				{Op: models.DiffAdded, To: 3, Lines: []models.LineDiff{
+					{Op: models.DiffAdded, New: "d"},
+				}},
+			},
+		},
+		{
+			name: "removed",
+			old:  "a\n\nb\n\nc",
+			new:  "a\n\nc",
+			want: []models.VerseDiff{
+				{Op: models.DiffRemoved, From: 2, Lines: []models.LineDiff{
+					{Op: models.DiffRemoved, Old: "b"},
+				}},
+			},
+		},
+		{
+			name: "annotation change",
+			old:  "a",
+			new:  "[Chorus]\na",
+			want: []models.VerseDiff{
+				{Op: models.DiffChanged, From: 1, To: 1, Lines: []models.LineDiff{
+					{Op: models.DiffAdded, New: "[Chorus]"},
+					{Op: models.DiffEqual, Old: "a", New: "a"},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffVerses(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("verses\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
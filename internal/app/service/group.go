@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"music/internal"
+	"music/internal/app/models"
+	"music/internal/config"
+	m "music/internal/rest/models"
+)
+
+type GroupRepository interface {
+	Create(ctx context.Context, p m.GroupParams) (models.Group, error)
+	Delete(ctx context.Context, id int32) error
+	Update(ctx context.Context, id int32, p m.GroupParams) (models.Group, error)
+	Get(ctx context.Context, id int32) (models.Group, error)
+	List(ctx context.Context, pageNum, perPage int) ([]models.Group, error)
+}
+
+type GroupService struct {
+	cfg    config.Config
+	logger *slog.Logger
+	repo   GroupRepository
+	songs  SongRepository
+}
+
+func NewGroupService(cfg config.Config, logger *slog.Logger, repo GroupRepository, songs SongRepository) *GroupService {
+	return &GroupService{
+		cfg:    cfg,
+		logger: logger,
+		repo:   repo,
+		songs:  songs,
+	}
+}
+
+func (s *GroupService) Create(ctx context.Context, p m.GroupParams) (models.Group, error) {
+	if err := p.Validate(); err != nil {
+		return models.Group{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service create group")
+	}
+
+	return s.repo.Create(ctx, p)
+}
+
+func (s *GroupService) Update(ctx context.Context, id int32, p m.GroupParams) (models.Group, error) {
+	if err := p.Validate(); err != nil {
+		return models.Group{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service update group")
+	}
+
+	return s.repo.Update(ctx, id, p)
+}
+
+func (s *GroupService) Delete(ctx context.Context, id int32) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *GroupService) Get(ctx context.Context, id int32) (models.Group, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *GroupService) List(ctx context.Context, pageNum, perPage int) ([]models.Group, error) {
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service list groups")
+	}
+
+	return s.repo.List(ctx, pageNum, perPage)
+}
+
+// Songs returns the songs of the group, the group must exist.
+func (s *GroupService) Songs(ctx context.Context, id int32, pageNum, perPage int) (models.SearchPage, error) {
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return models.SearchPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service group songs")
+	}
+
+	q := models.SearchQuery{
+		Criteria: []models.Criterion{{Field: "group_id", Op: models.OpEq, Values: []any{id}}},
+		Sort:     []models.SortKey{{Field: "release_date"}, {Field: "song_name"}},
+	}
+
+	// a missing group is told from a group without songs, a group deleted
+	// right after the check just has no songs listed
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return models.SearchPage{}, err
+	}
+
+	return searchPage(ctx, s.songs, q, pageNum, perPage)
+}
+
+// GroupSongsError reports songs keeping the group from deletion, songs in
+// the trash keep it until they are purged. Storage backends return it, so
+// the wording is the same for all of them.
+func GroupSongsError(id int32, live, trashed int) error {
+	switch {
+	case live > 0:
+		return internal.NewErrorf(internal.ErrorCodeConflict, "group with id %d has songs", id)
+	case trashed > 0:
+		return internal.NewErrorf(internal.ErrorCodeConflict, "group with id %d has %d songs in the trash until they are purged", id, trashed)
+	}
+
+	return nil
+}
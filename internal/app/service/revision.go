@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+)
+
+type RevisionRepository interface {
+	Add(ctx context.Context, songID int32, action models.RevisionAction, author string) error
+	List(ctx context.Context, songID int32, pageNum, perPage int) ([]models.Revision, error)
+	Get(ctx context.Context, songID, rev int32) (models.Revision, error)
+}
+
+// Revisions lists the song history, the latest revision first. Deleted songs
+// keep their history until purged.
+func (s *SongService) Revisions(ctx context.Context, id int32, pageNum, perPage int) ([]models.Revision, error) {
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service revisions")
+	}
+
+	revisions, err := s.revisions.List(ctx, id, pageNum, perPage)
+	if err != nil {
+		return nil, err
+	}
+	// every song has at least the revision made on create
+	if len(revisions) == 0 && pageNum == 0 {
+		return nil, internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+	}
+
+	return revisions, nil
+}
+
+// Revision returns the song revision numbered rev.
+func (s *SongService) Revision(ctx context.Context, id, rev int32) (models.Revision, error) {
+	return s.revisions.Get(ctx, id, rev)
+}
+
+// Revert makes a new revision of the song with the content of an older one,
+// a non-zero version must match the current one.
+func (s *SongService) Revert(ctx context.Context, id, rev, version int32) (models.Song, error) {
+	var song models.Song
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		old, err := s.revisions.Get(ctx, id, rev)
+		if err != nil {
+			return err
+		}
+
+		song, err = s.repo.Update(ctx, id, version, m.UpdateParams{
+			GroupID:     old.GroupID,
+			Group:       old.Group,
+			Name:        old.Name,
+			ReleaseDate: old.ReleaseDate.Format("02.01.2006"),
+			Text:        old.Text,
+			Link:        old.Link,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.revisions.Add(ctx, id, models.RevisionRevert, internal.Author(ctx))
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	return song, nil
+}
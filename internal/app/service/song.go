@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"music/internal"
@@ -8,37 +9,75 @@ import (
 	"music/internal/config"
 	m "music/internal/rest/models"
 	"net/url"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type SongRepository interface {
-	Create(params m.CreateParams) (models.Song, error)
-	Delete(id int32) error
-	Update(id int32, s m.UpdateParams) (models.Song, error)
-	SelectText(id int32) (string, error)
-	Search(vals url.Values, pageNum, perPage int) ([]models.Song, error)
+	Create(ctx context.Context, params m.CreateParams) (models.Song, error)
+	Delete(ctx context.Context, id, version int32) error
+	Update(ctx context.Context, id, version int32, s m.UpdateParams) (models.Song, error)
+	Patch(ctx context.Context, id, version int32, p m.PatchParams) (models.Song, error)
+	GetByID(ctx context.Context, id int32) (models.Song, error)
+	SelectText(ctx context.Context, id int32) (string, error)
+	Search(ctx context.Context, q models.SearchQuery, pageNum, perPage int) ([]models.Song, error)
+	Count(ctx context.Context, q models.SearchQuery) (int, error)
+	SearchText(ctx context.Context, text string, pageNum, perPage int) ([]models.LyricsMatch, error)
+	Suggest(ctx context.Context, text string, limit int) ([]models.Suggestion, error)
+	Trash(ctx context.Context, pageNum, perPage int) ([]models.TrashedSong, error)
+	Restore(ctx context.Context, id int32) (models.Song, error)
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
 
+// Transactor runs fn as a single unit of work: repository calls made with
+// the context passed to fn are committed together or not at all. A nested
+// call runs within a savepoint of the outer unit: its failure rolls back its
+// own changes only, and the outer unit decides whether the rest commits.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// maxSuggestions limits the number of autocomplete suggestions.
+const maxSuggestions = 50
+
 type SongService struct {
-	cfg    config.Config
-	logger *slog.Logger
-	repo   SongRepository
+	cfg       config.Config
+	logger    *slog.Logger
+	repo      SongRepository
+	revisions RevisionRepository
+	tx        Transactor
 }
 
-func NewSongService(cfg config.Config, logger *slog.Logger, repo SongRepository) *SongService {
+func NewSongService(
+	cfg config.Config, logger *slog.Logger, repo SongRepository, revisions RevisionRepository, tx Transactor,
+) *SongService {
 	return &SongService{
-		cfg:    cfg,
-		logger: logger,
-		repo:   repo,
+		cfg:       cfg,
+		logger:    logger,
+		repo:      repo,
+		revisions: revisions,
+		tx:        tx,
 	}
 }
 
-func (s *SongService) Create(params m.CreateParams) (models.Song, error) {
+func (s *SongService) Create(ctx context.Context, params m.CreateParams) (models.Song, error) {
 	if err := params.Validate(); err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service create")
 	}
 
-	song, err := s.repo.Create(params)
+	var song models.Song
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		song, err = s.repo.Create(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		return s.revisions.Add(ctx, song.ID, models.RevisionCreate, internal.Author(ctx))
+	})
 	if err != nil {
 		return models.Song{}, err
 	}
@@ -46,12 +85,22 @@ func (s *SongService) Create(params m.CreateParams) (models.Song, error) {
 	return song, nil
 }
 
-func (s *SongService) Update(id int32, p m.UpdateParams) (models.Song, error) {
+// Update replaces the song, a non-zero version must match the current one.
+func (s *SongService) Update(ctx context.Context, id, version int32, p m.UpdateParams) (models.Song, error) {
 	if err := p.Validate(); err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service update")
 	}
 
-	song, err := s.repo.Update(id, p)
+	var song models.Song
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		song, err = s.repo.Update(ctx, id, version, p)
+		if err != nil {
+			return err
+		}
+
+		return s.revisions.Add(ctx, song.ID, models.RevisionUpdate, internal.Author(ctx))
+	})
 	if err != nil {
 		return models.Song{}, err
 	}
@@ -59,23 +108,97 @@ func (s *SongService) Update(id int32, p m.UpdateParams) (models.Song, error) {
 	return song, nil
 }
 
-func (s *SongService) Delete(id int32) error {
-	if err := s.repo.Delete(id); err != nil {
-		return err
+// Patch changes the given song fields, a non-zero version must match the
+// current one.
+func (s *SongService) Patch(ctx context.Context, id, version int32, p m.PatchParams) (models.Song, error) {
+	if err := p.Validate(); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service patch")
 	}
 
-	return nil
+	var song models.Song
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		song, err = s.repo.Patch(ctx, id, version, p)
+		if err != nil {
+			return err
+		}
+		// an empty patch keeps the version, there is no new revision
+		if p.Empty() {
+			return nil
+		}
+
+		return s.revisions.Add(ctx, song.ID, models.RevisionUpdate, internal.Author(ctx))
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	return song, nil
+}
+
+// Delete moves the song to the trash, a non-zero version must match the
+// current one.
+func (s *SongService) Delete(ctx context.Context, id, version int32) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+
+		return s.revisions.Add(ctx, id, models.RevisionDelete, internal.Author(ctx))
+	})
+}
+
+// Trash lists deleted songs, the most recently deleted first.
+func (s *SongService) Trash(ctx context.Context, pageNum, perPage int) ([]models.TrashedSong, error) {
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service trash")
+	}
+
+	return s.repo.Trash(ctx, pageNum, perPage)
+}
+
+// Restore takes a deleted song out of the trash.
+func (s *SongService) Restore(ctx context.Context, id int32) (models.Song, error) {
+	var song models.Song
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		song, err = s.repo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.revisions.Add(ctx, song.ID, models.RevisionRestore, internal.Author(ctx))
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	return song, nil
+}
+
+// Purge permanently removes songs kept in the trash longer than the
+// configured retention and returns their number.
+func (s *SongService) Purge(ctx context.Context) (int, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-s.cfg.TrashRetention))
 }
 
-func (s *SongService) SelectVerse(id int32, v int) (string, error) {
-	text, err := s.repo.SelectText(id)
+func (s *SongService) GetByID(ctx context.Context, id int32) (models.Song, error) {
+	song, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	return song, nil
+}
+
+func (s *SongService) SelectVerse(ctx context.Context, id int32, v int) (string, error) {
+	sections, err := s.Sections(ctx, id)
 	if err != nil {
 		return "", err
 	}
 
-	verses := strings.Split(text, "\n\n")
-	if len(verses) < v {
-		return "", internal.NewErrorf(internal.ErrorCodeNotFound, "the song has only %d verses", len(verses))
+	if len(sections) < v {
+		return "", internal.NewErrorf(internal.ErrorCodeNotFound, "the song has only %d verses", len(sections))
 	}
 
 	// numeration from 1!
@@ -83,28 +206,372 @@ func (s *SongService) SelectVerse(id int32, v int) (string, error) {
 		return "", internal.NewErrorf(internal.ErrorCodeInvalidArgument, "verses must be numerated from 1")
 	}
 
-	return verses[v-1], nil
+	return sections[v-1].Text, nil
+}
+
+// Sections returns the song lyrics as ordered typed sections, repeated
+// ones refer to their first occurrence.
+func (s *SongService) Sections(ctx context.Context, id int32) ([]models.Section, error) {
+	text, err := s.repo.SelectText(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := models.ParseSections(text)
+	if sections == nil {
+		sections = []models.Section{}
+	}
+	markChoruses(sections)
+
+	return sections, nil
+}
+
+// Lyrics returns a page of the song sections numbered within the verses
+// range like 2-4, 3 or 2-. An empty range selects all sections, pages are
+// numbered from 0 as in other listings. Compact rendering replaces repeats
+// with markers like [Chorus x3].
+func (s *SongService) Lyrics(ctx context.Context, id int32, verses string, compact bool, pageNum, perPage int) (models.LyricsPage, error) {
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return models.LyricsPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service lyrics")
+	}
+
+	from, to, err := parseVerseRange(verses)
+	if err != nil {
+		return models.LyricsPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service lyrics")
+	}
+
+	sections, err := s.Sections(ctx, id)
+	if err != nil {
+		return models.LyricsPage{}, err
+	}
+
+	if verses != "" && from > len(sections) {
+		return models.LyricsPage{}, internal.NewErrorf(internal.ErrorCodeNotFound, "the song has only %d verses", len(sections))
+	}
+	if to == 0 || to > len(sections) {
+		to = len(sections)
+	}
+	selected := sections[from-1 : to]
+	if compact {
+		selected = compactSections(selected)
+	}
+
+	start := min(pageNum*perPage, len(selected))
+	end := min(start+perPage, len(selected))
+
+	return models.LyricsPage{
+		ID:       id,
+		Items:    selected[start:end],
+		Total:    len(sections),
+		Selected: len(selected),
+		Page:     pageNum,
+		PerPage:  perPage,
+	}, nil
+}
+
+// parseVerseRange parses a range of verse numbers like 2-4, a single verse
+// number or an open range like 2-. Zero end means the last verse.
+func parseVerseRange(v string) (int, int, error) {
+	if v == "" {
+		return 1, 0, nil
+	}
+
+	first, last, isRange := strings.Cut(v, "-")
+	from, err := strconv.Atoi(first)
+	if err != nil || from < 1 {
+		return 0, 0, fmt.Errorf("invalid verses range %q, verses are numerated from 1", v)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	if last == "" {
+		return from, 0, nil
+	}
+
+	to, err := strconv.Atoi(last)
+	if err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid verses range %q", v)
+	}
+
+	return from, to, nil
+}
+
+// splitVerses splits lyrics into sections, each kept with its annotation
+// line. Empty lyrics have none.
+func splitVerses(text string) []string {
+	sections := models.ParseSections(text)
+	verses := make([]string, len(sections))
+	for i, sec := range sections {
+		verses[i] = sec.String()
+	}
+
+	return verses
+}
+
+func (s *SongService) Search(ctx context.Context, vals url.Values, pageNum, perPage int) (models.SearchPage, error) {
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return models.SearchPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service search")
+	}
+
+	criteria, err := validateURLParams(vals)
+	if err != nil {
+		return models.SearchPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service search")
+	}
+
+	sortKeys, err := parseSort(vals.Get("sort"))
+	if err != nil {
+		return models.SearchPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service search")
+	}
+
+	return searchPage(ctx, s.repo, models.SearchQuery{Criteria: criteria, Sort: sortKeys}, pageNum, perPage)
+}
+
+// searchPage selects a page of songs along with the total number of them.
+func searchPage(ctx context.Context, repo SongRepository, q models.SearchQuery, pageNum, perPage int) (models.SearchPage, error) {
+	songs, err := repo.Search(ctx, q, pageNum, perPage)
+	if err != nil {
+		return models.SearchPage{}, err
+	}
+
+	total, err := repo.Count(ctx, q)
+	if err != nil {
+		return models.SearchPage{}, err
+	}
+
+	return models.SearchPage{
+		Items:   songs,
+		Total:   total,
+		Page:    pageNum,
+		PerPage: perPage,
+	}, nil
+}
+
+// List returns up to limit songs matching search params following the
+// cursor position, an empty cursor starts from the beginning.
+func (s *SongService) List(ctx context.Context, vals url.Values, cur string, limit int) (models.SongsPage, error) {
+	if err := validatePage(0, limit, s.cfg.MaxPageSize); err != nil {
+		return models.SongsPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service list")
+	}
+
+	criteria, err := validateURLParams(vals)
+	if err != nil {
+		return models.SongsPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service list")
+	}
+
+	sortKeys, err := parseSort(vals.Get("sort"))
+	if err != nil {
+		return models.SongsPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service list")
+	}
+
+	q := models.SearchQuery{Criteria: criteria, Sort: sortKeys}
+	keys := q.OrderKeys()
+	if cur != "" {
+		q.After, err = decodeCursor(keys, cur)
+		if err != nil {
+			return models.SongsPage{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service list")
+		}
+	}
+
+	// one extra song tells whether there is a next page
+	songs, err := s.repo.Search(ctx, q, 0, limit+1)
+	if err != nil {
+		return models.SongsPage{}, err
+	}
+
+	page := models.SongsPage{Items: songs}
+	if len(songs) > limit {
+		page.Items = songs[:limit]
+		page.NextCursor, err = encodeCursor(keys, page.Items[limit-1])
+		if err != nil {
+			return models.SongsPage{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "service list")
+		}
+	}
+
+	return page, nil
+}
+
+// SearchText runs a full-text lyrics search, text is a web search style
+// query: quoted phrases, or, -word.
+func (s *SongService) SearchText(ctx context.Context, text string, pageNum, perPage int) ([]models.LyricsMatch, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "empty search query")
+	}
+	if err := validatePage(pageNum, perPage, s.cfg.MaxPageSize); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service search text")
+	}
+
+	matches, err := s.repo.SearchText(ctx, text, pageNum, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
 }
 
-func (s *SongService) Search(vals url.Values, pageNum, perPage int) ([]models.Song, error) {
-	if err := validateURLParams(vals); err != nil {
-		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "service search")
+// Suggest returns at most limit song titles similar to the text, best first.
+func (s *SongService) Suggest(ctx context.Context, text string, limit int) ([]models.Suggestion, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "empty suggest query")
+	}
+	if limit < 1 || limit > maxSuggestions {
+		return nil, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "limit must be from 1 to %d", maxSuggestions)
 	}
 
-	songs, err := s.repo.Search(vals, pageNum, perPage)
+	suggestions, err := s.repo.Suggest(ctx, text, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	return songs, nil
+	return suggestions, nil
 }
 
-func validateURLParams(vals url.Values) error {
-	// Only one key-val pair required
-	for _, val := range vals {
+// textOps and orderedOps are the operators supported by text and by
+// numeric or date fields, names are also matched by similarity.
+var (
+	textOps = []models.Operator{
+		models.OpEq, models.OpIEq, models.OpPrefix, models.OpContains, models.OpILike, models.OpIn,
+	}
+	nameOps    = append(slices.Clone(textOps), models.OpSimilar)
+	orderedOps = []models.Operator{
+		models.OpEq, models.OpGt, models.OpGte, models.OpLt, models.OpLte, models.OpIn,
+	}
+)
+
+// searchFields maps searchable fields to the operators they support.
+var searchFields = map[string][]models.Operator{
+	"group_id":     orderedOps,
+	"group_name":   nameOps,
+	"song_name":    nameOps,
+	"release_date": orderedOps,
+	"song_text":    textOps,
+	"link":         textOps,
+}
+
+// sortFields are the fields search results can be ordered by.
+var sortFields = []string{"id", "group_id", "group_name", "song_name", "release_date", "link"}
+
+// searchOptions are search params other than filters.
+var searchOptions = []string{"sort", "cursor", "limit"}
+
+// validateURLParams parses search params like song_name[ilike]=black or
+// group_name[in]=Muse,Queen into criteria, a key without an operator is an
+// exact match. Empty values are ignored.
+func validateURLParams(vals url.Values) ([]models.Criterion, error) {
+	keys := make([]string, 0, len(vals))
+	for key, val := range vals {
+		// Only one key-val pair required
 		if len(val) > 1 {
-			return fmt.Errorf("invalid url params, %v", val)
+			return nil, fmt.Errorf("invalid url params, %v", val)
+		}
+		if slices.Contains(searchOptions, key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	// keep criteria order stable
+	sort.Strings(keys)
+
+	criteria := make([]models.Criterion, 0, len(keys))
+	for _, key := range keys {
+		val := vals[key][0]
+		if val == "" {
+			continue
+		}
+
+		field, op, err := parseSearchKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := []string{val}
+		if op == models.OpIn {
+			raw = strings.Split(val, ",")
+		}
+
+		values := make([]any, 0, len(raw))
+		for _, r := range raw {
+			v, err := parseSearchValue(field, r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q: %w", key, r, err)
+			}
+			values = append(values, v)
+		}
+
+		criteria = append(criteria, models.Criterion{Field: field, Op: op, Values: values})
+	}
+
+	return criteria, nil
+}
+
+// parseSort parses a comma separated list of sort fields, a field prefixed
+// with "-" is sorted in descending order, e.g. release_date,-song_name.
+func parseSort(val string) ([]models.SortKey, error) {
+	if val == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(val, ",")
+	keys := make([]models.SortKey, 0, len(fields))
+	for _, f := range fields {
+		key := models.SortKey{Field: strings.TrimSpace(f)}
+		if strings.HasPrefix(key.Field, "-") {
+			key.Field, key.Desc = key.Field[1:], true
 		}
+		if !slices.Contains(sortFields, key.Field) {
+			return nil, fmt.Errorf("can not sort by %q", f)
+		}
+		if slices.ContainsFunc(keys, func(k models.SortKey) bool { return k.Field == key.Field }) {
+			return nil, fmt.Errorf("duplicate sort field %q", key.Field)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// parseSearchKey splits a field[op] key and checks the field supports op.
+func parseSearchKey(key string) (string, models.Operator, error) {
+	field, op := key, models.OpEq
+	if i := strings.IndexByte(key, '['); i >= 0 {
+		if !strings.HasSuffix(key, "]") {
+			return "", "", fmt.Errorf("invalid search param %q", key)
+		}
+		field, op = key[:i], models.Operator(key[i+1:len(key)-1])
+	}
+
+	ops, ok := searchFields[field]
+	if !ok {
+		return "", "", fmt.Errorf("unknown search field %q", field)
+	}
+	if !slices.Contains(ops, op) {
+		return "", "", fmt.Errorf("operator %q is not supported by %q", op, field)
+	}
+
+	return field, op, nil
+}
+
+func parseSearchValue(field, val string) (any, error) {
+	switch field {
+	case "group_id":
+		id, err := strconv.ParseInt(val, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		return int32(id), nil
+
+	case "release_date":
+		return time.Parse("02.01.2006", val)
+	}
+
+	return val, nil
+}
+
+func validatePage(pageNum, perPage, maxPerPage int) error {
+	if pageNum < 0 {
+		return fmt.Errorf("invalid page number %d, pages are numerated from 0", pageNum)
+	}
+	if perPage < 1 || perPage > maxPerPage {
+		return fmt.Errorf("invalid records per page %d, must be from 1 to %d", perPage, maxPerPage)
 	}
 	return nil
 }
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"music/internal"
+	"music/internal/config"
+)
+
+func TestParseVerseRange(t *testing.T) {
+	tests := []struct {
+		v        string
+		from, to int
+		wantErr  bool
+	}{
+		{v: "", from: 1, to: 0},
+		{v: "3", from: 3, to: 3},
+		{v: "2-4", from: 2, to: 4},
+		{v: "2-2", from: 2, to: 2},
+		{v: "2-", from: 2, to: 0},
+		{v: "2-99", from: 2, to: 99},
+		{v: "4-2", wantErr: true},
+		{v: "0", wantErr: true},
+		{v: "0-2", wantErr: true},
+		{v: "-2", wantErr: true},
+		{v: "2-x", wantErr: true},
+		{v: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			from, to, err := parseVerseRange(tt.v)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("range %d-%d, want error", from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.from || to != tt.to {
+				t.Fatalf("range %d-%d, want %d-%d", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+// textRepo serves a single song text, other methods are not used.
+type textRepo struct {
+	SongRepository
+	text string
+}
+
+func (r textRepo) SelectText(ctx context.Context, id int32) (string, error) {
+	return r.text, nil
+}
+
+func TestLyrics(t *testing.T) {
+	cfg := config.Config{MaxPageSize: 100}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := NewSongService(cfg, logger, textRepo{text: "v1\n\nv2\n\nv3\n\nv4\n\nv5"}, nil, nil)
+
+	tests := []struct {
+		name             string
+		verses           string
+		pageNum, perPage int
+		want             []string
+		selected         int
+		code             internal.ErrorCode
+	}{
+		{name: "first page", pageNum: 0, perPage: 2, want: []string{"v1", "v2"}, selected: 5},
+		{name: "last page", pageNum: 2, perPage: 2, want: []string{"v5"}, selected: 5},
+		{name: "past the last page", pageNum: 3, perPage: 2, want: []string{}, selected: 5},
+		{name: "exact last page", pageNum: 1, perPage: 5, want: []string{}, selected: 5},
+		{name: "range", verses: "2-4", pageNum: 1, perPage: 2, want: []string{"v4"}, selected: 3},
+		{name: "single verse", verses: "3", pageNum: 0, perPage: 10, want: []string{"v3"}, selected: 1},
+		{name: "end out of range", verses: "4-99", pageNum: 0, perPage: 10, want: []string{"v4", "v5"}, selected: 2},
+		{name: "start out of range", verses: "6-", pageNum: 0, perPage: 10, code: internal.ErrorCodeNotFound},
+		{name: "reversed range", verses: "4-2", pageNum: 0, perPage: 10, code: internal.ErrorCodeInvalidArgument},
+		{name: "negative page", pageNum: -1, perPage: 10, code: internal.ErrorCodeInvalidArgument},
+		{name: "too many per page", pageNum: 0, perPage: 101, code: internal.ErrorCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := svc.Lyrics(context.Background(), 1, tt.verses, false, tt.pageNum, tt.perPage)
+			if tt.code != 0 {
+				var ierr *internal.Error
+				if !errors.As(err, &ierr) || ierr.Code() != tt.code {
+					t.Fatalf("error %v, want code %v", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, len(page.Items))
+			for i, sec := range page.Items {
+				got[i] = sec.Text
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("items %q, want %q", got, tt.want)
+			}
+			if page.Total != 5 || page.Selected != tt.selected || page.Page != tt.pageNum || page.PerPage != tt.perPage {
+				t.Fatalf("page %+v", page)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package internal
+
+import "context"
+
+type authorKey struct{}
+
+// WithAuthor returns a copy of ctx carrying the name of the user making
+// changes, it is recorded in song revisions.
+func WithAuthor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, authorKey{}, name)
+}
+
+// Author returns the name of the user making changes, empty if unknown.
+func Author(ctx context.Context) string {
+	name, _ := ctx.Value(authorKey{}).(string)
+	return name
+}
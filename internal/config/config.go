@@ -1,13 +1,35 @@
 package config
 
-import "github.com/ilyakaznacheev/cleanenv"
+import (
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
 
 type Config struct {
-	DbUrl      string `env:"DB_URL"`
+	// db or memory
+	Storage string `env:"STORAGE" env-default:"db"`
+	// PostgreSQL url or sqlite://path of an SQLite database file
+	DbUrl string `env:"DB_URL"`
+	// Connection pool settings
+	DbMaxConns          int32         `env:"DB_MAX_CONNS" env-default:"10"`
+	DbMinConns          int32         `env:"DB_MIN_CONNS" env-default:"0"`
+	DbHealthCheckPeriod time.Duration `env:"DB_HEALTH_CHECK_PERIOD" env-default:"1m"`
+	// One of cache_statement, cache_describe, describe_exec, exec, simple_protocol
+	DbQueryExecMode string `env:"DB_QUERY_EXEC_MODE" env-default:"cache_statement"`
+	// Prepared statements cached per connection
+	DbStatementCacheCapacity int `env:"DB_STATEMENT_CACHE_CAPACITY" env-default:"512"`
+
 	ServerAddr string `env:"SERVER_ADDR"`
-	ApiAddr    string `env:"API_ADDR"`
-	LogLevel   int    `env:"LOG_LEVEL"`
-	ApiPath    string `env:"API_PATH"`
+	// Deadline for handling a single request
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" env-default:"5s"`
+	ApiAddr        string        `env:"API_ADDR"`
+	LogLevel       int           `env:"LOG_LEVEL"`
+	ApiPath        string        `env:"API_PATH"`
+	// Upper bound of records per page in listings
+	MaxPageSize int `env:"MAX_PAGE_SIZE" env-default:"100"`
+	// Deleted songs older than this are removed by purge
+	TrashRetention time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
 }
 
 func NewConfig(path string) (*Config, error) {
@@ -21,6 +21,9 @@ const (
 	ErrorCodeInvalidArgument
 	ErrorCodeBadGateWay
 	ErrorCodeUniqueConstraints
+	ErrorCodePreconditionFailed
+	// ErrorCodeConflict reports a change conflicting with the stored state
+	ErrorCodeConflict
 )
 
 // WrapErrorf returns a wrapped error.
@@ -55,3 +58,14 @@ func (e *Error) Unwrap() error {
 func (e *Error) Code() ErrorCode {
 	return e.code
 }
+
+// DuplicateError reports a record clashing with an existing one, it is
+// wrapped by errors with ErrorCodeUniqueConstraints.
+type DuplicateError struct {
+	// ID of the existing record, 0 if unknown
+	ID int32
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate of record %d", e.ID)
+}
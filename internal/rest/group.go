@@ -0,0 +1,255 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"music/internal"
+	"music/internal/app/models"
+	"music/internal/config"
+	m "music/internal/rest/models"
+)
+
+type GroupService interface {
+	Create(ctx context.Context, p m.GroupParams) (models.Group, error)
+	Delete(ctx context.Context, id int32) error
+	Update(ctx context.Context, id int32, p m.GroupParams) (models.Group, error)
+	Get(ctx context.Context, id int32) (models.Group, error)
+	List(ctx context.Context, pageNum, perPage int) ([]models.Group, error)
+	Songs(ctx context.Context, id int32, pageNum, perPage int) (models.SearchPage, error)
+}
+
+type GroupHandler struct {
+	cfg    config.Config
+	logger *slog.Logger
+	svc    GroupService
+}
+
+func NewGroupHandler(cfg config.Config, logger *slog.Logger, svc GroupService) *GroupHandler {
+	return &GroupHandler{
+		cfg:    cfg,
+		logger: logger,
+		svc:    svc,
+	}
+}
+
+func (h *GroupHandler) Register(r *mux.Router) {
+	r.HandleFunc("/groups", h.create).Methods(http.MethodPost)
+	r.HandleFunc("/groups", h.list).Methods(http.MethodGet)
+	r.HandleFunc("/groups/{id}", h.get).Methods(http.MethodGet)
+	r.HandleFunc("/groups/{id}", h.update).Methods(http.MethodPut)
+	r.HandleFunc("/groups/{id}", h.delete).Methods(http.MethodDelete)
+	r.HandleFunc("/groups/{id}/songs", h.songs).Methods(http.MethodGet)
+}
+
+//	@Tags Группы
+//
+// @Description Create new group
+// @Accept		json
+// @Produce		json
+// @Param		json	body		m.GroupParams	true	    "input data"
+// @Success		201		{object}	models.Group		        "Created"
+// @Failure		400		{object}	rest.ErrorResponse	        "Bad request"
+// @Failure		500		{object}	rest.ErrorResponse	        "Internal error"
+// @Router		/groups [post]
+func (h *GroupHandler) create(w http.ResponseWriter, r *http.Request) {
+	var p m.GroupParams
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid group params")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+	defer r.Body.Close()
+
+	group, err := h.svc.Create(r.Context(), p)
+	if err != nil {
+		msg := fmt.Errorf("create group failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("POST request success, group created", "id", group.ID)
+	renderResponse(w, group, http.StatusCreated)
+}
+
+//	@Tags Группы
+//
+// @Description Get group
+// @Accept		json
+// @Produce		json
+// @Param		id		path		int		            true	    "Group ID"
+// @Success		200		{object}	models.Group		"ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/groups/{id} [get]
+func (h *GroupHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	group, err := h.svc.Get(r.Context(), int32(id))
+	if err != nil {
+		msg := fmt.Errorf("get group failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, group selected", "id", id)
+	renderResponse(w, group, http.StatusOK)
+}
+
+//	@Tags Группы
+//
+// @Description Rename group
+// @Accept		json
+// @Produce		json
+// @Param		id		path		int		            true    	    "Group ID"
+// @Param		json	body		m.GroupParams  	    true	        "input data"
+// @Success		200		{object}	models.Group		"ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/groups/{id} [put]
+func (h *GroupHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	var p m.GroupParams
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid group params")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+	defer r.Body.Close()
+
+	group, err := h.svc.Update(r.Context(), int32(id), p)
+	if err != nil {
+		msg := fmt.Errorf("update group failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("PUT request success, group updated", "id", id)
+	renderResponse(w, group, http.StatusOK)
+}
+
+//	@Tags Группы
+//
+// @Description Delete group, the group must have no songs, including songs in the trash
+// @Accept		json
+// @Produce		json
+// @Param		id		path		int		            true	    "Group ID"
+// @Success		200		{object}	nil      			"ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		409		{object}	rest.ErrorResponse	"Group has songs"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/groups/{id} [delete]
+func (h *GroupHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), int32(id)); err != nil {
+		msg := fmt.Errorf("delete group failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("DELETE request success, group deleted", "id", id)
+	w.WriteHeader(http.StatusOK)
+}
+
+//	@Tags Группы
+//
+// @Description List groups ordered by name
+// @Param		page_num		query		int		false	    "Page number from 0"
+// @Param		per_page		query		int		false	    "Records per page, 10 by default"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	[]models.Group	            "ok"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/groups  [get]
+func (h *GroupHandler) list(w http.ResponseWriter, r *http.Request) {
+	pageNum, perPage, err := pageParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	groups, err := h.svc.List(r.Context(), pageNum, perPage)
+	if err != nil {
+		msg := fmt.Errorf("list groups failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, groups found", "number", len(groups))
+	renderResponse(w, groups, http.StatusOK)
+}
+
+//	@Tags Группы
+//
+// @Description List songs of the group ordered by release date
+// @Param		id				path		int		true	    "Group ID"
+// @Param		page_num		query		int		false	    "Page number from 0"
+// @Param		per_page		query		int		false	    "Records per page, 10 by default"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	models.SearchPage	        "ok"
+// @Header		200		{string}	Link	                    "RFC 8288 page links"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse  	    "Not found"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/groups/{id}/songs  [get]
+func (h *GroupHandler) songs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	pageNum, perPage, err := pageParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	page, err := h.svc.Songs(r.Context(), int32(id), pageNum, perPage)
+	if err != nil {
+		msg := fmt.Errorf("group songs failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	setPageLinks(w, page, func(n int) (string, error) {
+		u := *r.URL
+		q := u.Query()
+		q.Set("page_num", strconv.Itoa(n))
+		q.Set("per_page", strconv.Itoa(perPage))
+		u.RawQuery = q.Encode()
+		return u.RequestURI(), nil
+	})
+
+	h.logger.Info("GET request success, group songs found", "id", id, "number", len(page.Items))
+	renderResponse(w, page, http.StatusOK)
+}
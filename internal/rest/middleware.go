@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"music/internal"
+)
+
+// Timeout sets a deadline on the request context, database queries and
+// remote calls made for the request are cancelled when it expires or the
+// client goes away.
+func Timeout(d time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// maxAuthorLen is the longest author name stored in song revisions.
+const maxAuthorLen = 100
+
+// Author passes the name of the user making changes from the X-Author header
+// to services, it is optional.
+func Author(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get("X-Author")
+		if len(name) > maxAuthorLen {
+			msg := internal.NewErrorf(internal.ErrorCodeInvalidArgument, "X-Author is longer than %d bytes", maxAuthorLen)
+			renderErrorResponse(w, msg.Error(), msg)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(internal.WithAuthor(r.Context(), name)))
+	})
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/go-playground/validator"
+)
+
+type GroupParams struct {
+	// Group name
+	Name string `json:"group_name" validate:"required,max=50" example:"Muse"`
+}
+
+func (g *GroupParams) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(g); err != nil {
+		return err
+	}
+
+	return nil
+}
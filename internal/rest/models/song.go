@@ -1,12 +1,16 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/go-playground/validator"
 )
 
 type SongDetails struct {
+	// Group ID, optional: picks one of several groups sharing the name
+	GroupID int32 `json:"group_id" example:"1"`
 	// Group name
 	Group string `json:"group" validate:"required" example:"Muse"`
 	// Song name
@@ -23,6 +27,7 @@ func (s *SongDetails) Validate() error {
 }
 
 type CreateParams struct {
+	GroupID     int32
 	Group       string `validate:"required"`
 	Name        string `validate:"required"`
 	ReleaseDate string `json:"releaseDate" validate:"required"`
@@ -45,6 +50,8 @@ func (s *CreateParams) Validate() error {
 }
 
 type UpdateParams struct {
+	// Group ID, optional: takes precedence over the group name
+	GroupID int32 `json:"group_id" example:"1"`
 	// Group name
 	Group string `json:"group_name" validate:"required" example:"Muse"`
 	// Song name
@@ -77,3 +84,63 @@ type Verse struct {
 	// verse text
 	Text string `json:"text" example:"Some text\n"`
 }
+
+// PatchParams is a JSON Merge Patch (RFC 7396) of a song, only supplied
+// fields are changed. All song fields are mandatory, so null values, which
+// mean removal in a merge patch, are rejected.
+type PatchParams struct {
+	// Group ID, takes precedence over the group name
+	GroupID *int32 `json:"group_id" validate:"omitempty,min=1" example:"1"`
+	// Group name
+	Group *string `json:"group_name" validate:"omitempty,min=1,max=50" example:"Muse"`
+	// Song name
+	Name *string `json:"song_name" validate:"omitempty,min=1,max=200" example:"Supermassive Black Hole"`
+	// Release date in 02.01.2006 format
+	ReleaseDate *string `json:"release_date" validate:"omitempty,min=1" example:"16.07.2006"`
+	// Song text
+	Text *string `json:"song_text" validate:"omitempty,min=1" example:"Some text\n\n Some text2\n"`
+	// URL link
+	Link *string `json:"link" validate:"omitempty,min=1" example:"http://example.org"`
+}
+
+func (s *PatchParams) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for key, val := range fields {
+		switch key {
+		case "group_id", "group_name", "song_name", "release_date", "song_text", "link":
+		default:
+			return fmt.Errorf("unknown field %q", key)
+		}
+		if string(val) == "null" {
+			return fmt.Errorf("field %q can not be removed", key)
+		}
+	}
+
+	// alias drops the method set to avoid recursion
+	type alias PatchParams
+	return json.Unmarshal(data, (*alias)(s))
+}
+
+// Empty tells whether the patch supplies no fields and changes nothing.
+func (s *PatchParams) Empty() bool {
+	return s.GroupID == nil && s.Group == nil && s.Name == nil && s.ReleaseDate == nil && s.Text == nil && s.Link == nil
+}
+
+func (s *PatchParams) Validate() error {
+	validate := validator.New()
+	if err := validate.Struct(s); err != nil {
+		return err
+	}
+
+	if s.ReleaseDate != nil {
+		if _, err := time.Parse("02.01.2006", *s.ReleaseDate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"music/internal"
+)
+
+// Default paging for listings with optional page_num and per_page query params.
+const (
+	defaultPageNum = 0
+	defaultPerPage = 10
+)
+
+// pageParams reads optional page_num and per_page query params.
+func pageParams(r *http.Request) (int, int, error) {
+	pageNum, err := intParam(r, "page_num", defaultPageNum)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	perPage, err := intParam(r, "per_page", defaultPerPage)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return pageNum, perPage, nil
+}
+
+// limitParam reads optional limit query param.
+func limitParam(r *http.Request, def int) (int, error) {
+	return intParam(r, "limit", def)
+}
+
+// intParam reads optional integer query param.
+func intParam(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid %s", name)
+	}
+
+	return n, nil
+}
+
+// boolParam reads optional boolean query param, false by default.
+func boolParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid %s", name)
+	}
+
+	return b, nil
+}
+
+// etag renders a song version as a strong entity tag.
+func etag(version int32) string {
+	return strconv.Quote(strconv.Itoa(int(version)))
+}
+
+// ifMatch reads optional If-Match header with a single entity tag made by
+// etag, 0 means any version. Tags etag never makes can not match.
+func ifMatch(r *http.Request) (int32, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	if strings.Contains(v, ",") {
+		return 0, internal.NewErrorf(internal.ErrorCodeInvalidArgument, "If-Match supports a single entity tag")
+	}
+
+	s, err := strconv.Unquote(v)
+	if err != nil || !strings.HasPrefix(v, `"`) {
+		return 0, internal.NewErrorf(internal.ErrorCodePreconditionFailed, "entity tag %s does not match", v)
+	}
+	version, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || version < 1 {
+		return 0, internal.NewErrorf(internal.ErrorCodePreconditionFailed, "entity tag %s does not match", v)
+	}
+
+	return int32(version), nil
+}
@@ -1,18 +1,23 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"music/internal"
+	"music/internal/app/models"
 )
 
 // ErrorResponse represents a response containing an error message.
 type ErrorResponse struct {
 	// Error string
 	Error string `json:"error" example:"error description"`
+	// ID of the existing record on conflict
+	ExistingID int32 `json:"existing_id,omitempty" example:"1"`
 }
 
 func renderErrorResponse(w http.ResponseWriter, msg string, err error) {
@@ -20,7 +25,9 @@ func renderErrorResponse(w http.ResponseWriter, msg string, err error) {
 	status := http.StatusInternalServerError
 
 	var ierr *internal.Error
-	if !errors.As(err, &ierr) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	} else if !errors.As(err, &ierr) {
 		resp.Error = "internal error"
 	} else {
 		switch ierr.Code() {
@@ -30,6 +37,16 @@ func renderErrorResponse(w http.ResponseWriter, msg string, err error) {
 			status = http.StatusBadRequest
 		case internal.ErrorCodeBadGateWay:
 			status = http.StatusBadGateway
+		case internal.ErrorCodePreconditionFailed:
+			status = http.StatusPreconditionFailed
+		case internal.ErrorCodeConflict:
+			status = http.StatusConflict
+		case internal.ErrorCodeUniqueConstraints:
+			status = http.StatusConflict
+			var dup *internal.DuplicateError
+			if errors.As(err, &dup) {
+				resp.ExistingID = dup.ID
+			}
 		}
 	}
 
@@ -51,3 +68,39 @@ func renderResponse(w http.ResponseWriter, res interface{}, status int) {
 		fmt.Println("error writing content")
 	}
 }
+
+// setPageLinks sets the RFC 8288 Link header with first, prev, next and last
+// page references, pageURL returns the URL of a page by its number.
+func setPageLinks(w http.ResponseWriter, page models.SearchPage, pageURL func(pageNum int) (string, error)) error {
+	last := 0
+	if page.Total > 0 {
+		last = (page.Total - 1) / page.PerPage
+	}
+
+	rels := []struct {
+		rel  string
+		num  int
+		show bool
+	}{
+		{"first", 0, true},
+		{"prev", min(page.Page-1, last), page.Page > 0},
+		{"next", page.Page + 1, page.Page < last},
+		{"last", last, true},
+	}
+
+	links := make([]string, 0, len(rels))
+	for _, l := range rels {
+		if !l.show {
+			continue
+		}
+		u, err := pageURL(l.num)
+		if err != nil {
+			return err
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u, l.rel))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+
+	return nil
+}
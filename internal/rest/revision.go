@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"music/internal"
+)
+
+// revisionParams reads id and rev path params.
+func revisionParams(r *http.Request) (int32, int32, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+	}
+
+	rev, err := strconv.ParseInt(mux.Vars(r)["rev"], 10, 32)
+	if err != nil {
+		return 0, 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid revision")
+	}
+
+	return int32(id), int32(rev), nil
+}
+
+//	@Tags Фонотека
+//
+// @Description История изменений песни, последняя ревизия первой. Version каждой записи - номер ревизии
+// @Param		id				path		int		true	    "Song ID"
+// @Param		page_num		query		int		false	    "Page number from 0"
+// @Param		per_page		query		int		false	    "Records per page, 10 by default"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	[]models.Revision	        "ok"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse  	    "Not found"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/songs/{id}/revisions  [get]
+func (h *SongHandler) revisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	pageNum, perPage, err := pageParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	revisions, err := h.svc.Revisions(r.Context(), int32(id), pageNum, perPage)
+	if err != nil {
+		msg := fmt.Errorf("list revisions failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, revisions found", "id", id, "number", len(revisions))
+	renderResponse(w, revisions, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Ревизия песни: её состояние после изменения
+// @Param		id		path		int		true	    "Song ID"
+// @Param		rev		path		int		true	    "Revision number"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	models.Revision	    "ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id}/revisions/{rev}  [get]
+func (h *SongHandler) revision(w http.ResponseWriter, r *http.Request) {
+	id, rev, err := revisionParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	revision, err := h.svc.Revision(r.Context(), id, rev)
+	if err != nil {
+		msg := fmt.Errorf("get revision failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, revision selected", "id", id, "rev", rev)
+	renderResponse(w, revision, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Вернуть песню к содержимому ревизии, изменение записывается новой ревизией
+// @Param		id			path		int		true	    "Song ID"
+// @Param		rev			path		int		true	    "Revision number"
+// @Param		If-Match	header		string	false	    "ETag of the song version to change"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	models.Song			"ok"
+// @Header		200		{string}	ETag				"Song version"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		409		{object}	rest.ErrorResponse	"Song already exists in the group"
+// @Failure		412		{object}	rest.ErrorResponse	"Precondition failed"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id}/revisions/{rev}/revert  [post]
+func (h *SongHandler) revert(w http.ResponseWriter, r *http.Request) {
+	id, rev, err := revisionParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		msg := fmt.Errorf("revert failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	song, err := h.svc.Revert(r.Context(), id, rev, version)
+	if err != nil {
+		msg := fmt.Errorf("revert failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("POST request success, record reverted", "id", id, "rev", rev)
+	w.Header().Set("ETag", etag(song.Version))
+	renderResponse(w, song, http.StatusOK)
+}
+
+// diffParams reads required from and to revision query params.
+func diffParams(r *http.Request) (int32, int32, error) {
+	q := r.URL.Query()
+
+	from, err := strconv.ParseInt(q.Get("from"), 10, 32)
+	if err != nil {
+		return 0, 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid from")
+	}
+
+	to, err := strconv.ParseInt(q.Get("to"), 10, 32)
+	if err != nil {
+		return 0, 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid to")
+	}
+
+	return int32(from), int32(to), nil
+}
+
+//	@Tags Фонотека
+//
+// @Description Построчное сравнение текста двух ревизий песни по куплетам. format=unified или Accept: text/x-diff возвращает unified diff
+// @Param		id		path		int		true	    "Song ID"
+// @Param		from	query		int		true	    "Older revision number"
+// @Param		to		query		int		true	    "Newer revision number"
+// @Param		format	query		string	false	    "json (default) or unified"
+// @Accept		json
+// @Produce		json
+// @Produce		text/x-diff
+// @Success		200		{object}	models.SongDiff	    "ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id}/diff  [get]
+func (h *SongHandler) diff(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	from, to, err := diffParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/x-diff") {
+		format = "unified"
+	}
+
+	switch format {
+	case "", "json":
+		d, err := h.svc.Diff(r.Context(), int32(id), from, to)
+		if err != nil {
+			msg := fmt.Errorf("diff failed: %w", err)
+			renderErrorResponse(w, msg.Error(), msg)
+			return
+		}
+
+		h.logger.Info("GET request success, revisions compared", "id", id, "from", from, "to", to)
+		renderResponse(w, d, http.StatusOK)
+
+	case "unified":
+		d, err := h.svc.UnifiedDiff(r.Context(), int32(id), from, to)
+		if err != nil {
+			msg := fmt.Errorf("diff failed: %w", err)
+			renderErrorResponse(w, msg.Error(), msg)
+			return
+		}
+
+		h.logger.Info("GET request success, revisions compared", "id", id, "from", from, "to", to)
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(d))
+
+	default:
+		msg := internal.NewErrorf(internal.ErrorCodeInvalidArgument, "unknown diff format %q", format)
+		renderErrorResponse(w, msg.Error(), msg)
+	}
+}
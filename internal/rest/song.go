@@ -1,9 +1,11 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -17,11 +19,25 @@ import (
 )
 
 type SongService interface {
-	Create(params m.CreateParams) (models.Song, error)
-	Delete(id int32) error
-	Update(id int32, f m.UpdateParams) (models.Song, error)
-	SelectVerse(id int32, v int) (string, error)
-	Search(params url.Values, pageNum, perPage int) ([]models.Song, error)
+	Create(ctx context.Context, params m.CreateParams) (models.Song, error)
+	Delete(ctx context.Context, id, version int32) error
+	Trash(ctx context.Context, pageNum, perPage int) ([]models.TrashedSong, error)
+	Restore(ctx context.Context, id int32) (models.Song, error)
+	Revisions(ctx context.Context, id int32, pageNum, perPage int) ([]models.Revision, error)
+	Revision(ctx context.Context, id, rev int32) (models.Revision, error)
+	Revert(ctx context.Context, id, rev, version int32) (models.Song, error)
+	Diff(ctx context.Context, id, from, to int32) (models.SongDiff, error)
+	UnifiedDiff(ctx context.Context, id, from, to int32) (string, error)
+	Update(ctx context.Context, id, version int32, f m.UpdateParams) (models.Song, error)
+	Patch(ctx context.Context, id, version int32, p m.PatchParams) (models.Song, error)
+	GetByID(ctx context.Context, id int32) (models.Song, error)
+	SelectVerse(ctx context.Context, id int32, v int) (string, error)
+	Sections(ctx context.Context, id int32) ([]models.Section, error)
+	Lyrics(ctx context.Context, id int32, verses string, compact bool, pageNum, perPage int) (models.LyricsPage, error)
+	Search(ctx context.Context, params url.Values, pageNum, perPage int) (models.SearchPage, error)
+	List(ctx context.Context, params url.Values, cursor string, limit int) (models.SongsPage, error)
+	SearchText(ctx context.Context, text string, pageNum, perPage int) ([]models.LyricsMatch, error)
+	Suggest(ctx context.Context, text string, limit int) ([]models.Suggestion, error)
 }
 
 type SongHandler struct {
@@ -40,9 +56,22 @@ func NewSongHandler(cfg config.Config, logger *slog.Logger, svc SongService) *So
 
 func (h *SongHandler) Register(r *mux.Router) {
 	r.HandleFunc("/songs", h.create).Methods(http.MethodPost)
+	r.HandleFunc("/songs", h.list).Methods(http.MethodGet)
+	r.HandleFunc("/songs/search", h.searchText).Methods(http.MethodGet)
+	r.HandleFunc("/songs/suggest", h.suggest).Methods(http.MethodGet)
+	r.HandleFunc("/songs/{id}", h.get).Methods(http.MethodGet)
 	r.HandleFunc("/songs/{id}", h.update).Methods(http.MethodPut)
+	r.HandleFunc("/songs/{id}", h.patch).Methods(http.MethodPatch)
 	r.HandleFunc("/songs/{id}", h.delete).Methods(http.MethodDelete)
+	r.HandleFunc("/songs/{id}/restore", h.restore).Methods(http.MethodPost)
+	r.HandleFunc("/songs/{id}/revisions", h.revisions).Methods(http.MethodGet)
+	r.HandleFunc("/songs/{id}/revisions/{rev}", h.revision).Methods(http.MethodGet)
+	r.HandleFunc("/songs/{id}/revisions/{rev}/revert", h.revert).Methods(http.MethodPost)
+	r.HandleFunc("/songs/{id}/diff", h.diff).Methods(http.MethodGet)
 	r.HandleFunc("/songs/{id}/verse/{vid}", h.getVerse).Methods(http.MethodGet)
+	r.HandleFunc("/songs/{id}/sections", h.sections).Methods(http.MethodGet)
+	r.HandleFunc("/songs/{id}/lyrics", h.lyrics).Methods(http.MethodGet)
+	r.HandleFunc("/trash/songs", h.trash).Methods(http.MethodGet)
 	r.HandleFunc("/songs/page/{page_num}/records/{per_page}", h.search).Methods(http.MethodGet)
 }
 
@@ -53,7 +82,9 @@ func (h *SongHandler) Register(r *mux.Router) {
 // @Produce		json
 // @Param		json	body		m.SongDetails	true	    "input data"
 // @Success		201		{object}	models.Song			        "Created"
+// @Header		201		{string}	ETag				        "Song version"
 // @Failure		400		{object}	rest.ErrorResponse	        "Bad request"
+// @Failure		409		{object}	rest.ErrorResponse	        "Song already exists in the group"
 // @Failure		500		{object}	rest.ErrorResponse	        "Internal error"
 // @Failure		502		{object}	rest.ErrorResponse	        "Bad Gateway"
 // @Router		/songs [post]
@@ -72,7 +103,7 @@ func (h *SongHandler) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	createParams, err := fetchDetails(h.cfg, h.logger, sd)
+	createParams, err := fetchDetails(r.Context(), h.cfg, h.logger, sd)
 	// fmt.Println("createSong: %v", createParams)
 	if err != nil {
 		msg := internal.WrapErrorf(err, internal.ErrorCodeBadGateWay, "create failed")
@@ -80,7 +111,7 @@ func (h *SongHandler) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	song, err := h.svc.Create(createParams)
+	song, err := h.svc.Create(r.Context(), createParams)
 	if err != nil {
 		msg := fmt.Errorf("create failed: %w", err)
 		renderErrorResponse(w, msg.Error(), msg)
@@ -88,19 +119,22 @@ func (h *SongHandler) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.logger.Info("POST request success, record created", "id", song.ID)
+	w.Header().Set("ETag", etag(song.Version))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(song)
 }
 
 //	@Tags Фонотека
 //
-// @Description Delete record
+// @Description Move record to the trash, it can be restored until purged
 // @Accept		json
 // @Produce		json
 // @Param		id		path		int		            true	    "Song ID"
+// @Param		If-Match	header	string	            false	    "ETag of the song version to delete"
 // @Success		200		{object}	nil      			"ok"
 // @Failure		400		{object}	rest.ErrorResponse	"Bad request"
 // @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		412		{object}	rest.ErrorResponse	"Precondition failed"
 // @Failure		500		{object}	rest.ErrorResponse	"Internal error"
 // @Router		/songs/{id} [delete]
 func (h *SongHandler) delete(w http.ResponseWriter, r *http.Request) {
@@ -111,7 +145,14 @@ func (h *SongHandler) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Delete(int32(id)); err != nil {
+	version, err := ifMatch(r)
+	if err != nil {
+		msg := fmt.Errorf("delete failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), int32(id), version); err != nil {
 		msg := fmt.Errorf("delete failed: %w", err)
 		renderErrorResponse(w, msg.Error(), msg)
 		return
@@ -120,6 +161,68 @@ func (h *SongHandler) delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+//	@Tags Фонотека
+//
+// @Description List deleted records, the most recently deleted first
+// @Param		page_num		query		int		false	    "Page number from 0"
+// @Param		per_page		query		int		false	    "Records per page, 10 by default"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	[]models.TrashedSong	    "ok"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/trash/songs  [get]
+func (h *SongHandler) trash(w http.ResponseWriter, r *http.Request) {
+	pageNum, perPage, err := pageParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	songs, err := h.svc.Trash(r.Context(), pageNum, perPage)
+	if err != nil {
+		msg := fmt.Errorf("list trash failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, deleted records found", "number", len(songs))
+	renderResponse(w, songs, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Restore deleted record from the trash
+// @Accept		json
+// @Produce		json
+// @Param		id		path		int		            true	    "Song ID"
+// @Success		200		{object}	models.Song			"ok"
+// @Header		200		{string}	ETag				"Song version"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		409		{object}	rest.ErrorResponse	"Song already exists in the group"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id}/restore [post]
+func (h *SongHandler) restore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	song, err := h.svc.Restore(r.Context(), int32(id))
+	if err != nil {
+		msg := fmt.Errorf("restore failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("POST request success, record restored", "id", id)
+	w.Header().Set("ETag", etag(song.Version))
+	renderResponse(w, song, http.StatusOK)
+}
+
 //	@Tags Фонотека
 //
 // @Description Update record
@@ -127,9 +230,13 @@ func (h *SongHandler) delete(w http.ResponseWriter, r *http.Request) {
 // @Produce		json
 // @Param		id		path		int		            true    	    "Song ID"
 // @Param		json	body		m.UpdateParams  	true	        "input data"
+// @Param		If-Match	header	string	            false	        "ETag of the song version to change"
 // @Success		200		{object}	models.Song			"ok"
+// @Header		200		{string}	ETag				"Song version"
 // @Failure		400		{object}	rest.ErrorResponse	"Bad request"
 // @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		409		{object}	rest.ErrorResponse	"Song already exists in the group"
+// @Failure		412		{object}	rest.ErrorResponse	"Precondition failed"
 // @Failure		500		{object}	rest.ErrorResponse	"Internal error"
 // @Router		/songs/{id} [put]
 func (h *SongHandler) update(w http.ResponseWriter, r *http.Request) {
@@ -148,13 +255,113 @@ func (h *SongHandler) update(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	song, err := h.svc.Update(int32(id), updateParams)
+	version, err := ifMatch(r)
+	if err != nil {
+		msg := fmt.Errorf("update failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	song, err := h.svc.Update(r.Context(), int32(id), version, updateParams)
 	if err != nil {
 		msg := fmt.Errorf("update failed: %w", err)
 		renderErrorResponse(w, msg.Error(), msg)
 		return
 	}
 	h.logger.Info("PUT request success, record updated", "id", id)
+	w.Header().Set("ETag", etag(song.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(song)
+}
+
+//	@Tags Фонотека
+//
+// @Description Partially update record with JSON Merge Patch (RFC 7396), only supplied fields are changed
+// @Accept		json
+// @Accept		application/merge-patch+json
+// @Produce		json
+// @Param		id		path		int		            true    	    "Song ID"
+// @Param		json	body		m.PatchParams  	    true	        "fields to change"
+// @Param		If-Match	header	string	            false	        "ETag of the song version to change"
+// @Success		200		{object}	models.Song			"ok"
+// @Header		200		{string}	ETag				"Song version"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		409		{object}	rest.ErrorResponse	"Song already exists in the group"
+// @Failure		412		{object}	rest.ErrorResponse	"Precondition failed"
+// @Failure		415		{object}	rest.ErrorResponse	"Unsupported media type"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id} [patch]
+func (h *SongHandler) patch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct != "" && ct != "application/merge-patch+json" && ct != "application/json" {
+		renderResponse(w, ErrorResponse{Error: "unsupported content type " + ct}, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var patchParams m.PatchParams
+	if err := json.NewDecoder(r.Body).Decode(&patchParams); err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid patch params")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+	defer r.Body.Close()
+
+	version, err := ifMatch(r)
+	if err != nil {
+		msg := fmt.Errorf("patch failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	song, err := h.svc.Patch(r.Context(), int32(id), version, patchParams)
+	if err != nil {
+		msg := fmt.Errorf("patch failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+	h.logger.Info("PATCH request success, record updated", "id", id)
+	w.Header().Set("ETag", etag(song.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(song)
+}
+
+//	@Tags Фонотека
+//
+// @Description Get record with full details
+// @Accept		json
+// @Produce		json
+// @Param		id		path		int		            true	    "Song ID"
+// @Success		200		{object}	models.Song			"ok"
+// @Header		200		{string}	ETag				"Song version"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id} [get]
+func (h *SongHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	song, err := h.svc.GetByID(r.Context(), int32(id))
+	if err != nil {
+		msg := fmt.Errorf("get failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, record selected", "id", id)
+	w.Header().Set("ETag", etag(song.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(song)
 }
@@ -187,7 +394,7 @@ func (h *SongHandler) getVerse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	v, err := h.svc.SelectVerse(int32(id), vid)
+	v, err := h.svc.SelectVerse(r.Context(), int32(id), vid)
 	if err != nil {
 		msg := fmt.Errorf("getVerse failed: %w", err)
 		renderErrorResponse(w, msg.Error(), msg)
@@ -199,7 +406,80 @@ func (h *SongHandler) getVerse(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(m.Verse{Num: strconv.Itoa(vid), Text: v})
 }
 
-func fetchDetails(cfg config.Config, logger *slog.Logger, sd m.SongDetails) (m.CreateParams, error) {
+//	@Tags Фонотека
+//
+// @Description Получить текст песни по частям: куплеты, припевы, бриджи, вступление и концовка
+// @Param		id		path		int		true	    "Song ID"
+// @Produce		json
+// @Success		200		{array}		models.Section	    "ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id}/sections  [get]
+func (h *SongHandler) sections(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	sections, err := h.svc.Sections(r.Context(), int32(id))
+	if err != nil {
+		msg := fmt.Errorf("sections failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, sections selected", "id", id)
+	renderResponse(w, sections, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Получить куплеты песни из диапазона номеров постранично
+// @Param		id			path		int		true	    "Song ID"
+// @Param		verses		query		string	false	    "Диапазон номеров куплетов: 2-4, 3 или 2-, по умолчанию все"
+// @Param		compact		query		bool	false	    "Заменить повторы припева ссылками вида [Chorus x3]"
+// @Param		page_num	query		int		false	    "Page number from 0"
+// @Param		per_page	query		int		false	    "Verses per page, 10 by default"
+// @Produce		json
+// @Success		200		{object}	models.LyricsPage	"ok"
+// @Failure		400		{object}	rest.ErrorResponse	"Bad request"
+// @Failure		404		{object}	rest.ErrorResponse	"Not found"
+// @Failure		500		{object}	rest.ErrorResponse	"Internal error"
+// @Router		/songs/{id}/lyrics  [get]
+func (h *SongHandler) lyrics(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid id")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	pageNum, perPage, err := pageParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+	compact, err := boolParam(r, "compact")
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	page, err := h.svc.Lyrics(r.Context(), int32(id), r.URL.Query().Get("verses"), compact, pageNum, perPage)
+	if err != nil {
+		msg := fmt.Errorf("lyrics failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, lyrics selected", "id", id, "number", len(page.Items))
+	renderResponse(w, page, http.StatusOK)
+}
+
+func fetchDetails(ctx context.Context, cfg config.Config, logger *slog.Logger, sd m.SongDetails) (m.CreateParams, error) {
 	q := make(url.Values)
 	q.Add("group", sd.Group)
 	q.Add("song", sd.Name)
@@ -210,7 +490,7 @@ func fetchDetails(cfg config.Config, logger *slog.Logger, sd m.SongDetails) (m.C
 		RawQuery: q.Encode(),
 	}
 	logger.Debug("remote api request", "url", url.String())
-	req, err := http.NewRequest("GET", url.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url.String(), nil)
 	if err != nil {
 		return m.CreateParams{}, fmt.Errorf("NewRequest error: %w", err)
 	}
@@ -233,6 +513,7 @@ func fetchDetails(cfg config.Config, logger *slog.Logger, sd m.SongDetails) (m.C
 		return m.CreateParams{}, fmt.Errorf("json decoder error: %w", err)
 	}
 
+	p.GroupID = sd.GroupID
 	p.Group = sd.Group
 	p.Name = sd.Name
 	return p, nil
@@ -240,19 +521,24 @@ func fetchDetails(cfg config.Config, logger *slog.Logger, sd m.SongDetails) (m.C
 
 //	@Tags Фонотека
 //
-// @Description Поиск по фонотеке
+// @Description Поиск по фонотеке. A filter key may carry an operator, e.g. song_name[ilike]=black,
+// @Description release_date[gte]=01.01.2005, group_name[in]=Muse,Queen. Text fields support eq (default),
+// @Description ieq, prefix, contains, ilike and in, group_name and song_name also support typo-tolerant similar;
+// @Description group_id and release_date support eq, gt, gte, lt, lte and in.
 // @Param		page_num		path		int		true	    "Page number from 0"
 // @Param		per_page		path		int		true	    "Records per page"
+// @Param		group_id		query		int		false	    "Group ID"
 // @Param		group_name		query		string	false	    "Searching group name"
 // @Param		song_name		query		string	false	    "Song name"
 // @Param		release_date	query		string	false	    "Release date (example 17.06.2006)"
 // @Param		song_text		query		string	false	    "Song text"
 // @Param		link    		query		string	false	    "Link"
+// @Param		sort    		query		string	false	    "Comma separated sort fields, - for descending, e.g. release_date,-song_name. Allowed: id, group_id, group_name, song_name, release_date, link"
 // @Accept		json
 // @Produce		json
-// @Success		200		{object}	[]models.Song	            "ok"
+// @Success		200		{object}	models.SearchPage	        "ok, Link header refers to first, prev, next and last pages"
+// @Header		200		{string}	Link	                    "RFC 8288 page links"
 // @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
-// @Failure		404		{object}	rest.ErrorResponse  	    "Not found"
 // @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
 // @Router		/songs/page/{page_num}/records/{per_page}  [get]
 func (h *SongHandler) search(w http.ResponseWriter, r *http.Request) {
@@ -277,14 +563,130 @@ func (h *SongHandler) search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	songs, err := h.svc.Search(m, pageNum, perPage)
+	page, err := h.svc.Search(r.Context(), m, pageNum, perPage)
 	if err != nil {
 		msg := fmt.Errorf("search failed: %w", err)
 		renderErrorResponse(w, msg.Error(), msg)
 		return
 	}
 
-	h.logger.Info("GET request success, records found", "number", len(songs))
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(songs)
+	route := mux.CurrentRoute(r)
+	if err := setPageLinks(w, page, func(n int) (string, error) {
+		u, err := route.URLPath("page_num", strconv.Itoa(n), "per_page", strconv.Itoa(perPage))
+		if err != nil {
+			return "", err
+		}
+		u.RawQuery = r.URL.RawQuery
+		return u.String(), nil
+	}); err != nil {
+		msg := fmt.Errorf("search failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, records found", "number", len(page.Items), "total", page.Total)
+	renderResponse(w, page, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Full-text lyrics search ordered by relevance, headlines highlight matching lines
+// @Param		q				query		string	true	    "Web search style query, e.g. soul or alight -baby"
+// @Param		page_num		query		int		false	    "Page number from 0"
+// @Param		per_page		query		int		false	    "Records per page, 10 by default"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	[]models.LyricsMatch	    "ok"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/songs/search  [get]
+func (h *SongHandler) searchText(w http.ResponseWriter, r *http.Request) {
+	pageNum, perPage, err := pageParams(r)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	matches, err := h.svc.SearchText(r.Context(), r.URL.Query().Get("q"), pageNum, perPage)
+	if err != nil {
+		msg := fmt.Errorf("search text failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, lyrics matched", "number", len(matches))
+	renderResponse(w, matches, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Autocomplete song titles, typo-tolerant: finds songs whose name or group name resemble the query
+// @Param		q				query		string	true	    "Query, e.g. Supermasive Black Hol"
+// @Param		limit			query		int		false	    "Number of suggestions, 10 by default"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	[]models.Suggestion	        "ok"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/songs/suggest  [get]
+func (h *SongHandler) suggest(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r, defaultPerPage)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	suggestions, err := h.svc.Suggest(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		msg := fmt.Errorf("suggest failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, suggestions found", "number", len(suggestions))
+	renderResponse(w, suggestions, http.StatusOK)
+}
+
+//	@Tags Фонотека
+//
+// @Description Список песен с курсорной пагинацией. Takes the same filters and sort as the page search,
+// @Description pass next_cursor of the response to get the next page with the same filters and sort.
+// @Param		cursor			query		string	false	    "Cursor from the previous page"
+// @Param		limit			query		int		false	    "Records per page, 10 by default"
+// @Param		sort    		query		string	false	    "Comma separated sort fields, - for descending, e.g. release_date,-song_name"
+// @Param		group_id		query		int		false	    "Group ID"
+// @Param		group_name		query		string	false	    "Searching group name"
+// @Param		song_name		query		string	false	    "Song name"
+// @Param		release_date	query		string	false	    "Release date (example 17.06.2006)"
+// @Param		song_text		query		string	false	    "Song text"
+// @Param		link    		query		string	false	    "Link"
+// @Accept		json
+// @Produce		json
+// @Success		200		{object}	models.SongsPage	        "ok"
+// @Failure		400		{object}	rest.ErrorResponse      	"Bad request"
+// @Failure		500		{object}	rest.ErrorResponse      	"Internal error"
+// @Router		/songs  [get]
+func (h *SongHandler) list(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r, defaultPerPage)
+	if err != nil {
+		renderErrorResponse(w, err.Error(), err)
+		return
+	}
+
+	q, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		msg := internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid url query")
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	page, err := h.svc.List(r.Context(), q, q.Get("cursor"), limit)
+	if err != nil {
+		msg := fmt.Errorf("list failed: %w", err)
+		renderErrorResponse(w, msg.Error(), msg)
+		return
+	}
+
+	h.logger.Info("GET request success, records listed", "number", len(page.Items))
+	renderResponse(w, page, http.StatusOK)
 }
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"music/internal"
+	"music/internal/app/models"
+	"music/internal/app/service"
+	m "music/internal/rest/models"
+)
+
+type GroupRepository struct {
+	store  *Store
+	logger *slog.Logger
+}
+
+func NewGroupRepo(store *Store, logger *slog.Logger) *GroupRepository {
+	return &GroupRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (r *GroupRepository) Create(ctx context.Context, p m.GroupParams) (models.Group, error) {
+	var g models.Group
+	err := r.store.write(ctx, func(d *data) error {
+		g = d.addGroup(p.Name)
+		return nil
+	})
+	if err != nil {
+		return models.Group{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create group")
+	}
+
+	r.logger.Debug("group created", "id", g.ID)
+
+	return g, nil
+}
+
+func (r *GroupRepository) Update(ctx context.Context, id int32, p m.GroupParams) (models.Group, error) {
+	err := r.store.write(ctx, func(d *data) error {
+		if _, ok := d.groups[id]; !ok {
+			return internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+		}
+		d.groups[id] = models.Group{ID: id, Name: p.Name}
+
+		return nil
+	})
+	if err != nil {
+		return models.Group{}, err
+	}
+
+	r.logger.Debug("group updated", "id", id)
+
+	return models.Group{ID: id, Name: p.Name}, nil
+}
+
+func (r *GroupRepository) Delete(ctx context.Context, id int32) error {
+	err := r.store.write(ctx, func(d *data) error {
+		if _, ok := d.groups[id]; !ok {
+			return internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+		}
+		// songs in the trash still refer to the group
+		var live, trashed int
+		for _, s := range d.songs {
+			switch {
+			case s.GroupID != id:
+			case s.deletedAt.IsZero():
+				live++
+			default:
+				trashed++
+			}
+		}
+		if err := service.GroupSongsError(id, live, trashed); err != nil {
+			return err
+		}
+		delete(d.groups, id)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Debug("group deleted", "id", id)
+	return nil
+}
+
+func (r *GroupRepository) Get(ctx context.Context, id int32) (models.Group, error) {
+	var (
+		g  models.Group
+		ok bool
+	)
+	r.store.read(func(d *data) {
+		g, ok = d.groups[id]
+	})
+	if !ok {
+		return models.Group{}, internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+	}
+
+	return g, nil
+}
+
+func (r *GroupRepository) List(ctx context.Context, pageNum, perPage int) ([]models.Group, error) {
+	var groups []models.Group
+	r.store.read(func(d *data) {
+		groups = slices.Collect(maps.Values(d.groups))
+	})
+
+	slices.SortFunc(groups, func(a, b models.Group) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	groups = page(groups, pageNum, perPage)
+
+	r.logger.Debug("groups selected", "count", len(groups))
+
+	return groups, nil
+}
+
+// addGroup creates a group with the name.
+func (d *data) addGroup(name string) models.Group {
+	d.lastGroupID++
+	g := models.Group{ID: d.lastGroupID, Name: name}
+	d.groups[g.ID] = g
+
+	return g
+}
+
+// resolveGroup returns the id and the name of the song group. A non-zero id
+// must refer to an existing group, otherwise the oldest group with the given
+// name is taken and created if there is none yet.
+func (d *data) resolveGroup(id int32, name string) (int32, string, error) {
+	if id != 0 {
+		g, ok := d.groups[id]
+		if !ok {
+			return 0, "", internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+		}
+
+		return g.ID, g.Name, nil
+	}
+
+	var oldest models.Group
+	for _, g := range d.groups {
+		if g.Name == name && (oldest.ID == 0 || g.ID < oldest.ID) {
+			oldest = g
+		}
+	}
+	if oldest.ID == 0 {
+		oldest = d.addGroup(name)
+	}
+
+	return oldest.ID, oldest.Name, nil
+}
+
+// page returns a copy of items on the page numbered from 0, it is empty
+// past the last page.
+func page[T any](items []T, pageNum, perPage int) []T {
+	start := min(pageNum*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	return append(make([]T, 0, end-start), items[start:end]...)
+}
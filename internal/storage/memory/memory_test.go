@@ -0,0 +1,27 @@
+package memory_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"music/internal/storage"
+	"music/internal/storage/memory"
+	"music/internal/storage/storagetest"
+)
+
+func TestRepositories(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	storagetest.Run(t, func(t *testing.T) *storage.Repositories {
+		store := memory.NewStore()
+
+		return &storage.Repositories{
+			Songs:     memory.NewSongRepo(store, logger),
+			Groups:    memory.NewGroupRepo(store, logger),
+			Revisions: memory.NewRevisionRepo(store, logger),
+			Tx:        memory.NewTransactor(store),
+			Close:     func() {},
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"music/internal"
+	"music/internal/app/models"
+)
+
+type RevisionRepository struct {
+	store  *Store
+	logger *slog.Logger
+}
+
+func NewRevisionRepo(store *Store, logger *slog.Logger) *RevisionRepository {
+	return &RevisionRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Add records the current state of the song, deleted or not, as the
+// revision numbered by its version.
+func (r *RevisionRepository) Add(ctx context.Context, songID int32, action models.RevisionAction, author string) error {
+	err := r.store.write(ctx, func(d *data) error {
+		s, ok := d.songs[songID]
+		if !ok {
+			return internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", songID)
+		}
+		// revisions are unique by the version like in the databases
+		if revs := d.revisions[songID]; len(revs) > 0 && revs[len(revs)-1].Version == s.Version {
+			return internal.NewErrorf(internal.ErrorCodeUnknown, "repo add revision: revision %d of song %d exists", s.Version, songID)
+		}
+
+		d.revisions[songID] = append(d.revisions[songID], models.Revision{
+			Song:      d.view(s),
+			Action:    action,
+			Author:    author,
+			CreatedAt: time.Now(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Debug("revision added", "id", songID, "action", action)
+
+	return nil
+}
+
+// List returns revisions of the song, the latest first.
+func (r *RevisionRepository) List(ctx context.Context, songID int32, pageNum, perPage int) ([]models.Revision, error) {
+	var revisions []models.Revision
+	r.store.read(func(d *data) {
+		revisions = slices.Clone(d.revisions[songID])
+	})
+
+	slices.Reverse(revisions)
+	revisions = page(revisions, pageNum, perPage)
+
+	r.logger.Debug("revisions selected", "id", songID, "count", len(revisions))
+
+	return revisions, nil
+}
+
+// Get returns the song revision numbered rev.
+func (r *RevisionRepository) Get(ctx context.Context, songID, rev int32) (models.Revision, error) {
+	var (
+		revision models.Revision
+		ok       bool
+	)
+	r.store.read(func(d *data) {
+		for _, v := range d.revisions[songID] {
+			if v.Version == rev {
+				revision, ok = v, true
+				break
+			}
+		}
+	})
+	if !ok {
+		return models.Revision{}, internal.NewErrorf(internal.ErrorCodeNotFound, "song %d has no revision %d", songID, rev)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"music/internal/app/models"
+	"music/internal/storage/trgm"
+)
+
+// search returns songs out of the trash matching all criteria of the query,
+// following its keyset position, in its order.
+func (d *data) search(sq models.SearchQuery) ([]models.Song, error) {
+	keys := sq.OrderKeys()
+	for _, k := range keys {
+		if _, err := sortValue(models.Song{}, k.Field); err != nil {
+			return nil, err
+		}
+	}
+	if sq.After != nil && len(sq.After) != len(keys) {
+		return nil, fmt.Errorf("keyset position has %d values, want %d", len(sq.After), len(keys))
+	}
+
+	songs := make([]models.Song, 0)
+	for _, s := range d.songs {
+		if !s.deletedAt.IsZero() {
+			continue
+		}
+		v := d.view(s)
+
+		ok, err := matchAll(sq.Criteria, v)
+		if err != nil {
+			return nil, err
+		}
+		if ok && sq.After != nil {
+			c, err := compareKeys(keys, v, sq.After)
+			if err != nil {
+				return nil, err
+			}
+			ok = c > 0
+		}
+		if ok {
+			songs = append(songs, v)
+		}
+	}
+
+	slices.SortFunc(songs, func(a, b models.Song) int {
+		c, _ := compareKeys(keys, a, keyValues(keys, b))
+		return c
+	})
+
+	return songs, nil
+}
+
+// fieldValue returns the song field searched by criteria: int32 for ids,
+// time.Time for the release date and string otherwise.
+func fieldValue(s models.Song, field string) (any, error) {
+	switch field {
+	case "id":
+		return s.ID, nil
+	case "group_id":
+		return s.GroupID, nil
+	case "group_name":
+		return s.Group, nil
+	case "song_name":
+		return s.Name, nil
+	case "release_date":
+		return s.ReleaseDate, nil
+	case "song_text":
+		return s.Text, nil
+	case "link":
+		return s.Link, nil
+	}
+
+	return nil, fmt.Errorf("unknown search field %q", field)
+}
+
+// sortValue returns the song field songs are ordered by, lyrics are not.
+func sortValue(s models.Song, field string) (any, error) {
+	if field == "song_text" {
+		return nil, fmt.Errorf("can not sort by %q", field)
+	}
+	v, err := fieldValue(s, field)
+	if err != nil {
+		return nil, fmt.Errorf("can not sort by %q", field)
+	}
+
+	return v, nil
+}
+
+// keyValues returns song fields of the order keys.
+func keyValues(keys []models.SortKey, s models.Song) []any {
+	vals := make([]any, len(keys))
+	for i, k := range keys {
+		vals[i], _ = sortValue(s, k.Field)
+	}
+
+	return vals
+}
+
+// compareKeys orders the song against values of the order keys.
+func compareKeys(keys []models.SortKey, s models.Song, vals []any) (int, error) {
+	for i, k := range keys {
+		v, err := sortValue(s, k.Field)
+		if err != nil {
+			return 0, err
+		}
+		c, err := compareValues(v, vals[i])
+		if err != nil {
+			return 0, err
+		}
+		if k.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// compareValues orders field values of the same type.
+func compareValues(a, b any) (int, error) {
+	switch a := a.(type) {
+	case int32:
+		if b, ok := b.(int32); ok {
+			return cmp.Compare(a, b), nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), nil
+		}
+	}
+
+	return 0, fmt.Errorf("can not compare %T with %T", a, b)
+}
+
+// matchAll reports whether the song matches all criteria.
+func matchAll(criteria []models.Criterion, s models.Song) (bool, error) {
+	for _, c := range criteria {
+		ok, err := match(c, s)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func match(c models.Criterion, s models.Song) (bool, error) {
+	v, err := fieldValue(s, c.Field)
+	if err != nil {
+		return false, err
+	}
+	if c.Op != models.OpIn && len(c.Values) != 1 {
+		return false, fmt.Errorf("operator %q takes one value", c.Op)
+	}
+
+	switch c.Op {
+	case models.OpEq, models.OpGt, models.OpGte, models.OpLt, models.OpLte:
+		order, err := compareValues(v, c.Values[0])
+		if err != nil {
+			return false, err
+		}
+		switch c.Op {
+		case models.OpEq:
+			return order == 0, nil
+		case models.OpGt:
+			return order > 0, nil
+		case models.OpGte:
+			return order >= 0, nil
+		case models.OpLt:
+			return order < 0, nil
+		default:
+			return order <= 0, nil
+		}
+
+	case models.OpIn:
+		for _, val := range c.Values {
+			order, err := compareValues(v, val)
+			if err != nil {
+				return false, err
+			}
+			if order == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case models.OpIEq, models.OpPrefix, models.OpContains, models.OpILike, models.OpSimilar:
+		text, ok := v.(string)
+		pattern, ok2 := c.Values[0].(string)
+		if !ok || !ok2 {
+			return false, fmt.Errorf("operator %q takes text", c.Op)
+		}
+		switch c.Op {
+		case models.OpIEq:
+			return strings.ToLower(text) == strings.ToLower(pattern), nil
+		case models.OpPrefix:
+			return strings.HasPrefix(text, pattern), nil
+		case models.OpContains:
+			return strings.Contains(text, pattern), nil
+		case models.OpILike:
+			return strings.Contains(strings.ToLower(text), strings.ToLower(pattern)), nil
+		default:
+			return trgm.Similarity(text, pattern) >= trgm.SimilarityThreshold, nil
+		}
+	}
+
+	return false, fmt.Errorf("unknown operator %q", c.Op)
+}
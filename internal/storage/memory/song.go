@@ -0,0 +1,434 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+	"music/internal/storage/trgm"
+	"music/internal/storage/websearch"
+)
+
+type SongRepository struct {
+	store  *Store
+	logger *slog.Logger
+}
+
+func NewSongRepo(store *Store, logger *slog.Logger) *SongRepository {
+	return &SongRepository{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (r *SongRepository) Create(ctx context.Context, p m.CreateParams) (models.Song, error) {
+	release, err := time.Parse("02.01.2006", p.ReleaseDate)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+	}
+
+	var s song
+	err = r.store.write(ctx, func(d *data) error {
+		groupID, _, err := d.resolveGroup(p.GroupID, p.Group)
+		if err != nil {
+			return err
+		}
+		if err := d.checkUnique(0, groupID, p.Name); err != nil {
+			return err
+		}
+
+		d.lastSongID++
+		s = song{Song: models.Song{
+			ID:          d.lastSongID,
+			GroupID:     groupID,
+			Name:        p.Name,
+			ReleaseDate: release,
+			Text:        models.NormalizeLyrics(p.Text),
+			Link:        p.Link,
+			Version:     1,
+		}}
+		d.songs[s.ID] = s
+
+		return nil
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	r.logger.Debug("record created", "id", s.ID)
+
+	return r.get(s.ID)
+}
+
+// Delete moves the song to the trash, a non-zero version must match the
+// current one.
+func (r *SongRepository) Delete(ctx context.Context, id, version int32) error {
+	err := r.store.write(ctx, func(d *data) error {
+		s, err := d.liveSong(id, version)
+		if err != nil {
+			return err
+		}
+
+		s.deletedAt = time.Now()
+		s.Version++
+		d.songs[id] = s
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Debug("record deleted", "id", id)
+	return nil
+}
+
+// Update replaces the song, a non-zero version must match the current one.
+func (r *SongRepository) Update(ctx context.Context, id, version int32, p m.UpdateParams) (models.Song, error) {
+	release, err := time.Parse("02.01.2006", p.ReleaseDate)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+	}
+
+	err = r.store.write(ctx, func(d *data) error {
+		s, err := d.liveSong(id, version)
+		if err != nil {
+			return err
+		}
+		groupID, _, err := d.resolveGroup(p.GroupID, p.Group)
+		if err != nil {
+			return err
+		}
+		if err := d.checkUnique(id, groupID, p.Name); err != nil {
+			return err
+		}
+
+		s.GroupID = groupID
+		s.Name = p.Name
+		s.ReleaseDate = release
+		s.Text = models.NormalizeLyrics(p.Text)
+		s.Link = p.Link
+		s.Version++
+		d.songs[id] = s
+
+		return nil
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	r.logger.Debug("record updated", "id", id)
+
+	return r.get(id)
+}
+
+// Patch changes the given song fields, a non-zero version must match the
+// current one.
+func (r *SongRepository) Patch(ctx context.Context, id, version int32, p m.PatchParams) (models.Song, error) {
+	var release time.Time
+	if p.ReleaseDate != nil {
+		var err error
+		release, err = time.Parse("02.01.2006", *p.ReleaseDate)
+		if err != nil {
+			return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+		}
+	}
+
+	err := r.store.write(ctx, func(d *data) error {
+		s, err := d.liveSong(id, version)
+		if err != nil {
+			return err
+		}
+		// an empty patch changes nothing, the version stays
+		if p.Empty() {
+			return nil
+		}
+
+		changed := s
+		if p.GroupID != nil || p.Group != nil {
+			var groupID int32
+			var group string
+			if p.GroupID != nil {
+				groupID = *p.GroupID
+			}
+			if p.Group != nil {
+				group = *p.Group
+			}
+			changed.GroupID, _, err = d.resolveGroup(groupID, group)
+			if err != nil {
+				return err
+			}
+		}
+		if p.Name != nil {
+			changed.Name = *p.Name
+		}
+		if p.ReleaseDate != nil {
+			changed.ReleaseDate = release
+		}
+		if p.Link != nil {
+			changed.Link = *p.Link
+		}
+		if p.Text != nil {
+			changed.Text = models.NormalizeLyrics(*p.Text)
+		}
+
+		if err := d.checkUnique(id, changed.GroupID, changed.Name); err != nil {
+			return err
+		}
+
+		changed.Version++
+		d.songs[id] = changed
+
+		return nil
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	r.logger.Debug("record patched", "id", id)
+
+	return r.get(id)
+}
+
+func (r *SongRepository) GetByID(ctx context.Context, id int32) (models.Song, error) {
+	song, err := r.get(id)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	r.logger.Debug("record selected", "id", id)
+
+	return song, nil
+}
+
+func (r *SongRepository) SelectText(ctx context.Context, id int32) (string, error) {
+	song, err := r.get(id)
+	if err != nil {
+		return "", err
+	}
+
+	r.logger.Debug("text selected", "id", id)
+
+	return song.Text, nil
+}
+
+// get returns the song unless it is in the trash.
+func (r *SongRepository) get(id int32) (models.Song, error) {
+	var (
+		s  song
+		ok bool
+	)
+	r.store.read(func(d *data) {
+		s, ok = d.songs[id]
+		s.Song = d.view(s)
+	})
+	if !ok || !s.deletedAt.IsZero() {
+		return models.Song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+	}
+
+	return s.Song, nil
+}
+
+func (r *SongRepository) Search(ctx context.Context, sq models.SearchQuery, pageNum, perPage int) ([]models.Song, error) {
+	var (
+		songs []models.Song
+		err   error
+	)
+	r.store.read(func(d *data) {
+		songs, err = d.search(sq)
+	})
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+	}
+	songs = page(songs, pageNum, perPage)
+
+	r.logger.Debug("records selected", "count", len(songs))
+
+	return songs, nil
+}
+
+// Count returns the number of songs matching the criteria of the query.
+func (r *SongRepository) Count(ctx context.Context, sq models.SearchQuery) (int, error) {
+	var (
+		songs []models.Song
+		err   error
+	)
+	r.store.read(func(d *data) {
+		songs, err = d.search(models.SearchQuery{Criteria: sq.Criteria})
+	})
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo count")
+	}
+
+	return len(songs), nil
+}
+
+// SearchText finds songs with verses matching the web search style query,
+// the most relevant first. It approximates the database full-text search:
+// operators are the same, but words are not stemmed.
+func (r *SongRepository) SearchText(ctx context.Context, text string, pageNum, perPage int) ([]models.LyricsMatch, error) {
+	query := websearch.Parse(text)
+	matches := make([]models.LyricsMatch, 0)
+
+	r.store.read(func(d *data) {
+		for _, s := range d.songs {
+			if !s.deletedAt.IsZero() {
+				continue
+			}
+			if lm, ok := matchLyrics(d.view(s), query); ok {
+				matches = append(matches, lm)
+			}
+		}
+	})
+
+	slices.SortFunc(matches, func(a, b models.LyricsMatch) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.ID, b.ID))
+	})
+	matches = page(matches, pageNum, perPage)
+
+	r.logger.Debug("lyrics matched", "count", len(matches))
+
+	return matches, nil
+}
+
+// Suggest returns song titles where the song or the group name resemble the
+// text, typos and unfinished words allowed.
+func (r *SongRepository) Suggest(ctx context.Context, text string, limit int) ([]models.Suggestion, error) {
+	suggestions := make([]models.Suggestion, 0, limit)
+
+	r.store.read(func(d *data) {
+		for _, s := range d.songs {
+			if !s.deletedAt.IsZero() {
+				continue
+			}
+			v := d.view(s)
+			score := max(trgm.WordSimilarity(text, v.Name), trgm.WordSimilarity(text, v.Group))
+			if score < trgm.WordSimilarityThreshold {
+				continue
+			}
+			suggestions = append(suggestions, models.Suggestion{
+				ID:      v.ID,
+				GroupID: v.GroupID,
+				Group:   v.Group,
+				Name:    v.Name,
+				Score:   score,
+			})
+		}
+	})
+
+	slices.SortFunc(suggestions, func(a, b models.Suggestion) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+	suggestions = suggestions[:min(limit, len(suggestions))]
+
+	r.logger.Debug("suggestions selected", "count", len(suggestions))
+
+	return suggestions, nil
+}
+
+// Trash returns deleted songs, the most recently deleted first.
+func (r *SongRepository) Trash(ctx context.Context, pageNum, perPage int) ([]models.TrashedSong, error) {
+	songs := make([]models.TrashedSong, 0)
+
+	r.store.read(func(d *data) {
+		for _, s := range d.songs {
+			if !s.deletedAt.IsZero() {
+				songs = append(songs, models.TrashedSong{Song: d.view(s), DeletedAt: s.deletedAt})
+			}
+		}
+	})
+
+	slices.SortFunc(songs, func(a, b models.TrashedSong) int {
+		return cmp.Or(b.DeletedAt.Compare(a.DeletedAt), cmp.Compare(a.ID, b.ID))
+	})
+	songs = page(songs, pageNum, perPage)
+
+	r.logger.Debug("trash selected", "count", len(songs))
+
+	return songs, nil
+}
+
+// Restore takes the song out of the trash.
+func (r *SongRepository) Restore(ctx context.Context, id int32) (models.Song, error) {
+	err := r.store.write(ctx, func(d *data) error {
+		s, ok := d.songs[id]
+		if !ok || s.deletedAt.IsZero() {
+			return internal.NewErrorf(internal.ErrorCodeNotFound, "song with id %d is not in the trash", id)
+		}
+		if err := d.checkUnique(id, s.GroupID, s.Name); err != nil {
+			return err
+		}
+
+		s.deletedAt = time.Time{}
+		s.Version++
+		d.songs[id] = s
+
+		return nil
+	})
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	r.logger.Debug("record restored", "id", id)
+
+	return r.get(id)
+}
+
+// Purge permanently removes songs deleted before the given time and returns
+// their number.
+func (r *SongRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	var n int
+	err := r.store.write(ctx, func(d *data) error {
+		for id, s := range d.songs {
+			if !s.deletedAt.IsZero() && s.deletedAt.Before(before) {
+				delete(d.songs, id)
+				delete(d.revisions, id)
+				n++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo purge")
+	}
+
+	r.logger.Debug("trash purged", "count", n)
+
+	return n, nil
+}
+
+// liveSong returns the song unless it is in the trash, a non-zero version
+// must match the current one.
+func (d *data) liveSong(id, version int32) (song, error) {
+	s, ok := d.songs[id]
+	if !ok || !s.deletedAt.IsZero() {
+		return song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+	}
+	if version != 0 && version != s.Version {
+		return song{}, internal.NewErrorf(internal.ErrorCodePreconditionFailed, "song %d has version %d, not %d", id, s.Version, version)
+	}
+
+	return s, nil
+}
+
+// checkUnique fails with ErrorCodeUniqueConstraints if a song other than id
+// has the name in the group, names differing in case only are the same.
+func (d *data) checkUnique(id, groupID int32, name string) error {
+	for _, s := range d.songs {
+		if s.ID != id && s.deletedAt.IsZero() && s.GroupID == groupID && strings.EqualFold(s.Name, name) {
+			return internal.WrapErrorf(
+				&internal.DuplicateError{ID: s.ID}, internal.ErrorCodeUniqueConstraints,
+				"the group already has a song with this name",
+			)
+		}
+	}
+
+	return nil
+}
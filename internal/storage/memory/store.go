@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"music/internal/app/models"
+)
+
+// Store keeps songs, groups and revisions in memory and is shared by the
+// repositories. Writes are serialized, a transaction holds off writes of
+// others until it ends and is rolled back by restoring a copy of the data.
+// Reads are not isolated, they see changes of unfinished transactions.
+type Store struct {
+	// writeMu is held by the outermost transaction or a single write
+	writeMu sync.Mutex
+	// mu guards data
+	mu   sync.RWMutex
+	data data
+}
+
+type data struct {
+	groups    map[int32]models.Group
+	songs     map[int32]song
+	revisions map[int32][]models.Revision
+
+	lastGroupID int32
+	lastSongID  int32
+}
+
+// song is a stored song, the group name is taken from groups on reads.
+type song struct {
+	models.Song
+	// Zero unless the song is in the trash
+	deletedAt time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		data: data{
+			groups:    make(map[int32]models.Group),
+			songs:     make(map[int32]song),
+			revisions: make(map[int32][]models.Revision),
+		},
+	}
+}
+
+// clone copies the data deep enough to be restored after changes.
+func (d *data) clone() data {
+	c := *d
+	c.groups = maps.Clone(d.groups)
+	c.songs = maps.Clone(d.songs)
+	c.revisions = make(map[int32][]models.Revision, len(d.revisions))
+	for id, revs := range d.revisions {
+		c.revisions[id] = slices.Clip(revs)
+	}
+
+	return c
+}
+
+// view returns the song as it is read, with the current group name.
+func (d *data) view(s song) models.Song {
+	v := s.Song
+	v.Group = d.groups[s.GroupID].Name
+
+	return v
+}
+
+// txKey marks contexts of transactions of a store.
+type txKey struct {
+	store *Store
+}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	return ctx.Value(txKey{s}) != nil
+}
+
+// read runs fn with the data locked for reading.
+func (s *Store) read(fn func(d *data)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fn(&s.data)
+}
+
+// write runs fn with the data locked for writing, fn must check everything
+// before changing the data. Outside of a transaction it waits for running
+// transactions to end.
+func (s *Store) write(ctx context.Context, fn func(d *data) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !s.inTx(ctx) {
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fn(&s.data)
+}
+
+// Transactor runs several repository operations as a single unit of work.
+type Transactor struct {
+	store *Store
+}
+
+func NewTransactor(store *Store) *Transactor {
+	return &Transactor{store: store}
+}
+
+// WithinTransaction runs fn in a transaction, repositories called with the
+// context passed to fn take part in it. Changes made by fn are undone when it
+// returns an error or panics, the panic is propagated. Nested calls undo only
+// their own changes.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	s := t.store
+	if !s.inTx(ctx) {
+		s.writeMu.Lock()
+		defer s.writeMu.Unlock()
+		ctx = context.WithValue(ctx, txKey{s}, true)
+	}
+
+	s.mu.RLock()
+	saved := s.data.clone()
+	s.mu.RUnlock()
+
+	defer func() {
+		p := recover()
+		if p != nil || err != nil {
+			s.mu.Lock()
+			s.data = saved
+			s.mu.Unlock()
+		}
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	return fn(ctx)
+}
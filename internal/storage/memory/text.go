@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"music/internal/app/models"
+	"music/internal/storage/trgm"
+	"music/internal/storage/websearch"
+)
+
+// matchLyrics finds verses matching the query. Rank grows with the share
+// of matched words in those verses, the headline joins them with the words
+// wrapped in <b></b>.
+func matchLyrics(s models.Song, q websearch.Query) (models.LyricsMatch, bool) {
+	if q.Empty() {
+		return models.LyricsMatch{}, false
+	}
+	terms := q.Words()
+
+	var (
+		rank      float32
+		headlines []string
+	)
+	for _, sec := range models.ParseSections(s.Text) {
+		verse := sec.Text
+		verseWords := trgm.Words(verse)
+		if !q.Match(verseWords) {
+			continue
+		}
+
+		found := 0
+		for _, w := range verseWords {
+			if slices.Contains(terms, w) {
+				found++
+			}
+		}
+		rank += float32(found) / float32(len(verseWords))
+		headlines = append(headlines, highlight(verse, terms))
+	}
+	if len(headlines) == 0 {
+		return models.LyricsMatch{}, false
+	}
+
+	return models.LyricsMatch{Song: s, Rank: rank, Headline: strings.Join(headlines, " ... ")}, true
+}
+
+// highlight wraps words of the verse equal to any of the terms in <b></b>.
+func highlight(verse string, terms []string) string {
+	var sb strings.Builder
+	word := make([]rune, 0, 16)
+	flush := func() {
+		w := string(word)
+		for _, t := range terms {
+			if strings.ToLower(w) == t {
+				w = "<b>" + w + "</b>"
+				break
+			}
+		}
+		sb.WriteString(w)
+		word = word[:0]
+	}
+
+	for _, r := range verse {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		sb.WriteRune(r)
+	}
+	flush()
+
+	return sb.String()
+}
@@ -0,0 +1,164 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"music/internal"
+	"music/internal/app/models"
+	"music/internal/app/service"
+	m "music/internal/rest/models"
+)
+
+// Postgres error codes of constraint violations.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+type GroupRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewGroupRepo(db *pgxpool.Pool, logger *slog.Logger) *GroupRepository {
+	return &GroupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *GroupRepository) Create(ctx context.Context, p m.GroupParams) (models.Group, error) {
+	var id int32
+	if err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`INSERT INTO public.groups
+		    (group_name)
+		VALUES
+		    ($1)
+		RETURNING id;`,
+		p.Name,
+	).Scan(&id); err != nil {
+		return models.Group{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create group")
+	}
+
+	r.logger.Debug("group created", "id", id)
+
+	return models.Group{ID: id, Name: p.Name}, nil
+}
+
+func (r *GroupRepository) Update(ctx context.Context, id int32, p m.GroupParams) (models.Group, error) {
+	result, err := conn(ctx, r.db).Exec(
+		ctx,
+		`UPDATE
+		    public.groups
+		SET
+		    group_name = $1
+		WHERE
+		    id = $2;`,
+		p.Name, id,
+	)
+	if err != nil {
+		return models.Group{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update group")
+	}
+	if result.RowsAffected() != 1 {
+		return models.Group{}, internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+	}
+
+	r.logger.Debug("group updated", "id", id)
+
+	return models.Group{ID: id, Name: p.Name}, nil
+}
+
+// Delete removes the group unless songs, even in the trash, refer to it.
+func (r *GroupRepository) Delete(ctx context.Context, id int32) error {
+	var live, trashed int
+	if err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT
+		    count(*) FILTER (WHERE deleted_at IS NULL),
+		    count(*) FILTER (WHERE deleted_at IS NOT NULL)
+		FROM public.songs
+		WHERE
+		    group_id = $1;`,
+		id,
+	).Scan(&live, &trashed); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete group")
+	}
+	if err := service.GroupSongsError(id, live, trashed); err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).Exec(ctx, "DELETE FROM public.groups WHERE id = $1", id)
+	if err != nil {
+		// a song added meanwhile
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return service.GroupSongsError(id, 1, 0)
+		}
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete group")
+	}
+	if result.RowsAffected() != 1 {
+		return internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+	}
+
+	r.logger.Debug("group deleted", "id", id)
+	return nil
+}
+
+func (r *GroupRepository) Get(ctx context.Context, id int32) (models.Group, error) {
+	g := models.Group{ID: id}
+	err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT
+		    group_name FROM public.groups
+		WHERE
+		    id = $1;`,
+		id,
+	).Scan(&g.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Group{}, internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+	}
+	if err != nil {
+		return models.Group{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo get group")
+	}
+
+	return g, nil
+}
+
+func (r *GroupRepository) List(ctx context.Context, pageNum, perPage int) ([]models.Group, error) {
+	groups := make([]models.Group, 0)
+
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT
+		    id, group_name FROM public.groups
+		ORDER BY group_name, id
+		LIMIT $1 OFFSET $2;`,
+		perPage, pageNum*perPage,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo list groups")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g models.Group
+		if err := rows.Scan(&g.ID, &g.Name); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo list groups")
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo list groups")
+	}
+
+	r.logger.Debug("groups selected", "count", len(groups))
+
+	return groups, nil
+}
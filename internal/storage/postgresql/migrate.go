@@ -0,0 +1,50 @@
+package postgresql
+
+import (
+	"errors"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	migrations "music/db"
+)
+
+// Migrate applies all pending migrations embedded from db/migrations.
+func Migrate(pool *pgxpool.Pool) error {
+	src, err := iofs.New(migrations.Migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	// migrate works with database/sql, wrap the pool
+	db := stdlib.OpenDBFromPool(pool)
+
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
+	if err != nil {
+		src.Close()
+		db.Close()
+		return err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "music", driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return err
+	}
+
+	// Apply all pending migrations, the schema may be behind by several steps
+	upErr := m.Up()
+
+	// The driver holds a pooled connection until closed, closing it closes
+	// the wrapper too
+	srcErr, dbErr := m.Close()
+
+	if upErr != nil && !errors.Is(upErr, migrate.ErrNoChange) {
+		return upErr
+	}
+
+	return errors.Join(srcErr, dbErr)
+}
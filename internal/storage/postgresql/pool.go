@@ -0,0 +1,53 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"music/internal/config"
+)
+
+// queryExecModes maps DB_QUERY_EXEC_MODE values to pgx modes.
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// NewPool opens a connection pool configured by cfg and checks the database
+// is reachable.
+func NewPool(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DbUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, ok := queryExecModes[cfg.DbQueryExecMode]
+	if !ok {
+		return nil, fmt.Errorf("unknown query exec mode %q", cfg.DbQueryExecMode)
+	}
+
+	poolCfg.MaxConns = cfg.DbMaxConns
+	poolCfg.MinConns = cfg.DbMinConns
+	poolCfg.HealthCheckPeriod = cfg.DbHealthCheckPeriod
+	poolCfg.ConnConfig.DefaultQueryExecMode = mode
+	poolCfg.ConnConfig.StatementCacheCapacity = cfg.DbStatementCacheCapacity
+	poolCfg.ConnConfig.DescriptionCacheCapacity = cfg.DbStatementCacheCapacity
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
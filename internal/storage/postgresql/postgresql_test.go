@@ -0,0 +1,53 @@
+package postgresql_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"music/internal/storage"
+	"music/internal/storage/postgresql"
+	"music/internal/storage/storagetest"
+)
+
+// TestRepositories runs the conformance suite against the database at
+// TEST_DB_URL. Its tables are emptied before every test.
+func TestRepositories(t *testing.T) {
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	storagetest.Run(t, func(t *testing.T) *storage.Repositories {
+		ctx := context.Background()
+		db, err := pgxpool.New(ctx, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := postgresql.Migrate(db); err != nil {
+			db.Close()
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(
+			ctx,
+			`TRUNCATE public.song_revisions, public.verses, public.songs, public.groups
+			RESTART IDENTITY CASCADE;`,
+		); err != nil {
+			db.Close()
+			t.Fatal(err)
+		}
+
+		return &storage.Repositories{
+			Songs:     postgresql.NewSongRepo(db, logger),
+			Groups:    postgresql.NewGroupRepo(db, logger),
+			Revisions: postgresql.NewRevisionRepo(db, logger),
+			Tx:        postgresql.NewTransactor(db, logger),
+			Close:     db.Close,
+		}
+	})
+}
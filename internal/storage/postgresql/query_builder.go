@@ -1,74 +1,229 @@
 package postgresql
 
 import (
-	"net/url"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
-	"time"
 )
 
+// identifier matches column names allowed in filters and ORDER BY terms.
+var identifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// Query builds a parameterized statement. Values never get into the SQL text,
+// they are collected in args and referenced with $n placeholders.
 type Query struct {
 	builder strings.Builder
+	args    []any
 }
 
-func NewQuery(fields []string, s, limit, offset string, vals url.Values) (*Query, error) {
-	var builder strings.Builder
-	params := make(map[string]string)
-
-	builder.WriteString(s)
-
-	for _, key := range fields {
-		// fmt.Println("key: ", key)
-
-		var v string
-		var val []string
-		var ok bool
-		switch key {
-		case "group_name", "song_name", "song_text", "link":
-			val, ok = vals[key]
-			if ok {
-				v = val[0]
-			}
-
-		case "release_date":
-			val, ok = vals[key]
-			if ok {
-				t, err := time.Parse("02.01.2006", val[0])
-				if err != nil {
-					return nil, err
-				}
-				v = t.Format("2006-01-02")
-			}
-		}
+// NewQuery starts a query with the given statement head, e.g. SELECT ... FROM ...
+func NewQuery(s string) *Query {
+	q := &Query{}
+	q.builder.WriteString(s)
 
-		if v != "" {
-			params[key] = v
-		}
+	return q
+}
+
+// Arg adds a query argument and returns its placeholder.
+func (q *Query) Arg(v any) string {
+	q.args = append(q.args, v)
+
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// Where appends the WHERE clause, an empty filter adds nothing.
+func (q *Query) Where(f Filter) error {
+	if f == nil {
+		return nil
 	}
 
-	filter := ""
-	for key, val := range params {
-		if filter == "" {
-			filter += " WHERE"
-		} else {
-			filter += " AND"
-		}
+	cond, err := f.render(q)
+	if err != nil {
+		return err
+	}
+	if cond != "" {
+		q.builder.WriteString(" WHERE ")
+		q.builder.WriteString(cond)
+	}
+
+	return nil
+}
 
-		filter += " " + key + "='" + val + "'"
+// OrderBy appends the ORDER BY clause, terms are column names optionally
+// followed by ASC or DESC.
+func (q *Query) OrderBy(terms ...string) error {
+	if len(terms) == 0 {
+		return nil
 	}
 
-	builder.WriteString(filter)
-	builder.WriteString(" ORDER BY group_name ")
-	builder.WriteString(" LIMIT ")
-	builder.WriteString(limit)
-	builder.WriteString(" OFFSET ")
-	builder.WriteString(offset)
-	builder.WriteString(";")
-	return &Query{
-		builder: builder,
-	}, nil
+	for _, t := range terms {
+		col, dir, _ := strings.Cut(t, " ")
+		if !identifier.MatchString(col) {
+			return fmt.Errorf("invalid order column %q", col)
+		}
+		if dir != "" && dir != "ASC" && dir != "DESC" {
+			return fmt.Errorf("invalid order direction %q", dir)
+		}
+	}
+
+	q.builder.WriteString(" ORDER BY ")
+	q.builder.WriteString(strings.Join(terms, ", "))
+
+	return nil
+}
 
+// Limit appends LIMIT and OFFSET clauses.
+func (q *Query) Limit(limit, offset int) {
+	q.builder.WriteString(" LIMIT ")
+	q.builder.WriteString(q.Arg(limit))
+	q.builder.WriteString(" OFFSET ")
+	q.builder.WriteString(q.Arg(offset))
 }
 
 func (q *Query) GetQuery() string {
-	return q.builder.String()
+	return q.builder.String() + ";"
+}
+
+func (q *Query) Args() []any {
+	return q.args
+}
+
+// Filter is a node of a WHERE clause tree.
+type Filter interface {
+	// render returns the SQL condition, its values are added to q args.
+	render(q *Query) (string, error)
+}
+
+type condition struct {
+	column string
+	op     string
+	val    any
+	// fold compares lower-cased values
+	fold bool
+}
+
+// Equal matches rows where column equals val.
+func Equal(column string, val any) Filter {
+	return condition{column: column, op: "=", val: val}
+}
+
+// Compare matches rows where column relates to val by op, one of
+// =, <>, <, <=, >, >=.
+func Compare(column, op string, val any) Filter {
+	return condition{column: column, op: op, val: val}
+}
+
+// Like matches column against a LIKE pattern, see LikeEscape.
+func Like(column, pattern string) Filter {
+	return condition{column: column, op: "LIKE", val: pattern}
+}
+
+// ILike is the case-insensitive Like.
+func ILike(column, pattern string) Filter {
+	return condition{column: column, op: "ILIKE", val: pattern}
+}
+
+// Similar matches rows where column is similar to val by trigrams,
+// see pg_trgm.
+func Similar(column, val string) Filter {
+	return condition{column: column, op: "%", val: val}
+}
+
+// EqualFold matches rows where column equals val ignoring case.
+func EqualFold(column, val string) Filter {
+	return condition{column: column, op: "=", val: val, fold: true}
+}
+
+// LikeEscape escapes LIKE wildcards in s so it matches literally.
+func LikeEscape(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (c condition) render(q *Query) (string, error) {
+	if !identifier.MatchString(c.column) {
+		return "", fmt.Errorf("invalid filter column %q", c.column)
+	}
+
+	switch c.op {
+	case "=", "<>", "<", "<=", ">", ">=", "LIKE", "ILIKE", "%":
+	default:
+		return "", fmt.Errorf("invalid filter operator %q", c.op)
+	}
+
+	if c.fold {
+		return "lower(" + c.column + ") " + c.op + " lower(" + q.Arg(c.val) + ")", nil
+	}
+
+	return c.column + " " + c.op + " " + q.Arg(c.val), nil
+}
+
+type in struct {
+	column string
+	vals   []any
+}
+
+// In matches rows where column equals any of vals.
+func In(column string, vals ...any) Filter {
+	return in{column: column, vals: vals}
+}
+
+// render matches nothing for an empty list.
+func (f in) render(q *Query) (string, error) {
+	if !identifier.MatchString(f.column) {
+		return "", fmt.Errorf("invalid filter column %q", f.column)
+	}
+	if len(f.vals) == 0 {
+		return "1 = 0", nil
+	}
+
+	placeholders := make([]string, len(f.vals))
+	for i, v := range f.vals {
+		placeholders[i] = q.Arg(v)
+	}
+
+	return f.column + " IN (" + strings.Join(placeholders, ", ") + ")", nil
+}
+
+type group struct {
+	op      string
+	filters []Filter
+}
+
+// And matches rows satisfying all filters.
+func And(filters ...Filter) Filter {
+	return group{op: " AND ", filters: filters}
+}
+
+// Or matches rows satisfying any of filters.
+func Or(filters ...Filter) Filter {
+	return group{op: " OR ", filters: filters}
+}
+
+// render skips empty members, a group without members renders empty.
+func (g group) render(q *Query) (string, error) {
+	parts := make([]string, 0, len(g.filters))
+	for _, f := range g.filters {
+		if f == nil {
+			continue
+		}
+		p, err := f.render(q)
+		if err != nil {
+			return "", err
+		}
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		return parts[0], nil
+	}
+
+	return "(" + strings.Join(parts, g.op) + ")", nil
 }
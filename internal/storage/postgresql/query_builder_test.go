@@ -0,0 +1,104 @@
+package postgresql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fuzzSeeds = []struct {
+	column, val string
+}{
+	{"song_name", "Uprising"},
+	{"s.group_name", "Muse'; DROP TABLE public.songs; --"},
+	{"song_name", `100% \_ pure' OR '1'='1`},
+	{"song_name", "$1"},
+	{"song_name); DROP TABLE public.songs; --", "x"},
+	{"Song_Name", "x"},
+	{"a.b.c", "x"},
+	{"", ""},
+}
+
+// FuzzFilter checks values only reach the query as arguments and columns
+// are either identifiers or rejected.
+func FuzzFilter(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s.column, s.val)
+	}
+
+	f.Fuzz(func(t *testing.T, column, val string) {
+		filter := And(
+			Equal(column, val),
+			Like(column, val),
+			ILike(column, val),
+			In(column, val, val+"'"),
+			EqualFold(column, val),
+		)
+
+		q := NewQuery("SELECT id FROM public.songs_view")
+		err := q.Where(filter)
+		if !identifier.MatchString(column) {
+			if err == nil {
+				t.Fatalf("column %q accepted: %s", column, q.GetQuery())
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("column %q: %v", column, err)
+		}
+
+		want := "SELECT id FROM public.songs_view WHERE (" +
+			column + " = $1 AND " +
+			column + " LIKE $2 AND " +
+			column + " ILIKE $3 AND " +
+			column + " IN ($4, $5) AND " +
+			"lower(" + column + ") = lower($6));"
+		if got := q.GetQuery(); got != want {
+			t.Fatalf("query %q, want %q", got, want)
+		}
+		if val != "" && strings.Contains(q.GetQuery(), val) && !strings.Contains(want, val) {
+			t.Fatalf("value %q got into the query %q", val, q.GetQuery())
+		}
+
+		args := []any{val, val, val, val, val + "'", val}
+		if !reflect.DeepEqual(q.Args(), args) {
+			t.Fatalf("args %q, want %q", q.Args(), args)
+		}
+	})
+}
+
+// FuzzLikeEscape checks escaped strings have no wildcards and match the
+// original string literally.
+func FuzzLikeEscape(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s.val)
+	}
+	f.Add(`\%_\\%%__`)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		pattern := LikeEscape(s)
+
+		// read the pattern the way LIKE with the default \ escape does
+		var literal strings.Builder
+		escaped := false
+		for i := 0; i < len(pattern); i++ {
+			switch b := pattern[i]; {
+			case escaped:
+				literal.WriteByte(b)
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '%' || b == '_':
+				t.Fatalf("pattern %q of %q has a wildcard", pattern, s)
+			default:
+				literal.WriteByte(b)
+			}
+		}
+		if escaped {
+			t.Fatalf("pattern %q of %q ends with an escape", pattern, s)
+		}
+		if literal.String() != s {
+			t.Fatalf("pattern %q matches %q, want %q", pattern, literal.String(), s)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"music/internal"
+	"music/internal/app/models"
+)
+
+// revisionColumns lists song_revisions columns in the order expected by
+// scanRevision.
+const revisionColumns = "song_id, group_id, group_name, song_name, release_date, song_text, link, rev, action, author, created_at"
+
+type RevisionRepository struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewRevisionRepo(db *pgxpool.Pool, logger *slog.Logger) *RevisionRepository {
+	return &RevisionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Add records the current state of the song, deleted or not, as the
+// revision numbered by its version.
+func (r *RevisionRepository) Add(ctx context.Context, songID int32, action models.RevisionAction, author string) error {
+	if _, err := conn(ctx, r.db).Exec(
+		ctx,
+		`INSERT INTO public.song_revisions
+		    (song_id, rev, action, author, group_id, group_name, song_name, release_date, song_text, link)
+		SELECT
+		    id, version, $2, $3, group_id, group_name, song_name, release_date, song_text, link
+		FROM public.all_songs_view
+		WHERE
+		    id = $1;`,
+		songID, action, author,
+	); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo add revision")
+	}
+
+	r.logger.Debug("revision added", "id", songID, "action", action)
+
+	return nil
+}
+
+// List returns revisions of the song, the latest first.
+func (r *RevisionRepository) List(ctx context.Context, songID int32, pageNum, perPage int) ([]models.Revision, error) {
+	revisions := make([]models.Revision, 0)
+
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT
+		    `+revisionColumns+` FROM public.song_revisions
+		WHERE
+		    song_id = $1
+		ORDER BY rev DESC
+		LIMIT $2 OFFSET $3;`,
+		songID, perPage, pageNum*perPage,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo list revisions")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo list revisions")
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo list revisions")
+	}
+
+	r.logger.Debug("revisions selected", "id", songID, "count", len(revisions))
+
+	return revisions, nil
+}
+
+// Get returns the song revision numbered rev.
+func (r *RevisionRepository) Get(ctx context.Context, songID, rev int32) (models.Revision, error) {
+	revision, err := scanRevision(conn(ctx, r.db).QueryRow(
+		ctx,
+		"SELECT "+revisionColumns+" FROM public.song_revisions WHERE song_id = $1 AND rev = $2;",
+		songID, rev,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Revision{}, internal.NewErrorf(internal.ErrorCodeNotFound, "song %d has no revision %d", songID, rev)
+		}
+		return models.Revision{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo get revision")
+	}
+
+	return revision, nil
+}
+
+// scanRevision reads a row selected with revisionColumns.
+func scanRevision(row scanner) (models.Revision, error) {
+	var rev models.Revision
+	err := row.Scan(
+		&rev.ID,
+		&rev.GroupID,
+		&rev.Group,
+		&rev.Name,
+		&rev.ReleaseDate,
+		&rev.Text,
+		&rev.Link,
+		&rev.Version,
+		&rev.Action,
+		&rev.Author,
+		&rev.CreatedAt,
+	)
+
+	return rev, err
+}
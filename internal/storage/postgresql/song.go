@@ -1,44 +1,72 @@
 package postgresql
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"net/url"
-	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
 	"music/internal"
 	"music/internal/app/models"
 	m "music/internal/rest/models"
 )
 
+// songColumns lists songs_view columns in the order expected by scanSong.
+const songColumns = "id, group_id, group_name, song_name, release_date, song_text, link, version"
+
 type SongRepository struct {
-	db     *sql.DB
+	db     *pgxpool.Pool
 	logger *slog.Logger
 }
 
-func NewSongRepo(db *sql.DB, logger *slog.Logger) *SongRepository {
+func NewSongRepo(db *pgxpool.Pool, logger *slog.Logger) *SongRepository {
 	return &SongRepository{
 		db:     db,
 		logger: logger,
 	}
 }
 
-func (r *SongRepository) Create(p m.CreateParams) (models.Song, error) {
+func (r *SongRepository) Create(ctx context.Context, p m.CreateParams) (models.Song, error) {
 	var id int32
 	release, err := time.Parse("02.01.2006", p.ReleaseDate)
 	if err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
 	}
 
-	if err := r.db.QueryRow(
-		`INSERT INTO public.songs 
-		    (group_name, song_name, release_date, song_text, link) 
-		VALUES 
-		    ($1, $2, $3, $4, $5) 
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
+	}
+	defer tx.Rollback(ctx)
+
+	groupID, group, err := resolveGroup(ctx, tx, p.GroupID, p.Group)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	if err := tx.QueryRow(
+		ctx,
+		`INSERT INTO public.songs
+		    (group_id, song_name, release_date, link)
+		VALUES
+		    ($1, $2, $3, $4)
 		RETURNING id;`,
-		p.Group, p.Name, release, p.Text, p.Link,
+		groupID, p.Name, release, p.Link,
 	).Scan(&id); err != nil {
+		return models.Song{}, r.duplicateError(ctx, tx, err, 0, &groupID, &p.Name, "repo create")
+	}
+
+	if err := insertVerses(ctx, tx, id, p.Text); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
 	}
 
@@ -46,78 +74,217 @@ func (r *SongRepository) Create(p m.CreateParams) (models.Song, error) {
 
 	return models.Song{
 		ID:          id,
-		Group:       p.Group,
+		GroupID:     groupID,
+		Group:       group,
 		Name:        p.Name,
 		ReleaseDate: release,
-		Text:        p.Text,
+		Text:        models.NormalizeLyrics(p.Text),
 		Link:        p.Link,
+		Version:     1,
 	}, nil
 }
 
-func (r *SongRepository) Delete(id int32) error {
-	result, err := r.db.Exec("DELETE FROM public.songs WHERE id = $1", id)
+// Delete moves the song to the trash, a non-zero version must match the
+// current one.
+func (r *SongRepository) Delete(ctx context.Context, id, version int32) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete")
 	}
-	deleted, err := result.RowsAffected()
-	if err != nil {
+	defer tx.Rollback(ctx)
+
+	if err := lockSong(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		"UPDATE public.songs SET deleted_at = now(), version = version + 1 WHERE id = $1;", id,
+	); err != nil {
 		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete")
 	}
-	if deleted != 1 {
-		return internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+
+	if err := tx.Commit(ctx); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete")
 	}
 
 	r.logger.Debug("record deleted", "id", id)
 	return nil
 }
 
-func (r *SongRepository) Update(id int32, p m.UpdateParams) (models.Song, error) {
+// Update replaces the song, a non-zero version must match the current one.
+func (r *SongRepository) Update(ctx context.Context, id, version int32, p m.UpdateParams) (models.Song, error) {
 	release, err := time.Parse("02.01.2006", p.ReleaseDate)
 	if err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
 	}
-	result, err := r.db.Exec(
-		`UPDATE
-		    public.songs 
-		SET 
-		    group_name = $1, song_name = $2, release_date = $3, 
-			song_text = $4, link = $5
-		WHERE
-		   id = $6;`,
-		p.Group, p.Name, release, p.Text, p.Link, id,
-	)
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
 	}
-	updated, err := result.RowsAffected()
+	defer tx.Rollback(ctx)
+
+	if err := lockSong(ctx, tx, id, version); err != nil {
+		return models.Song{}, err
+	}
+
+	groupID, group, err := resolveGroup(ctx, tx, p.GroupID, p.Group)
 	if err != nil {
+		return models.Song{}, err
+	}
+
+	if err := tx.QueryRow(
+		ctx,
+		`UPDATE
+		    public.songs
+		SET
+		    group_id = $1, song_name = $2, release_date = $3, link = $4, version = version + 1
+		WHERE
+		   id = $5
+		RETURNING version;`,
+		groupID, p.Name, release, p.Link, id,
+	).Scan(&version); err != nil {
+		return models.Song{}, r.duplicateError(ctx, tx, err, id, &groupID, &p.Name, "repo update")
+	}
+
+	if err := replaceVerses(ctx, tx, id, p.Text); err != nil {
 		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
 	}
-	if updated != 1 {
-		return models.Song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
 	}
 
 	r.logger.Debug("record updated", "id", id)
 
 	return models.Song{
 		ID:          id,
-		Group:       p.Group,
+		GroupID:     groupID,
+		Group:       group,
 		Name:        p.Name,
 		ReleaseDate: release,
-		Text:        p.Text,
+		Text:        models.NormalizeLyrics(p.Text),
 		Link:        p.Link,
+		Version:     version,
 	}, nil
 }
 
-func (r *SongRepository) SelectText(id int32) (string, error) {
+// Patch changes the given song fields, a non-zero version must match the
+// current one.
+func (r *SongRepository) Patch(ctx context.Context, id, version int32, p m.PatchParams) (models.Song, error) {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the row, it also tells a missing song from an empty patch
+	if err := lockSong(ctx, tx, id, version); err != nil {
+		return models.Song{}, err
+	}
+
+	sets := make([]string, 0, 4)
+	args := make([]any, 0, 5)
+	set := func(column string, val any) {
+		args = append(args, val)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	var newGroupID *int32
+	if p.GroupID != nil || p.Group != nil {
+		var groupID int32
+		var group string
+		if p.GroupID != nil {
+			groupID = *p.GroupID
+		}
+		if p.Group != nil {
+			group = *p.Group
+		}
+		groupID, _, err = resolveGroup(ctx, tx, groupID, group)
+		if err != nil {
+			return models.Song{}, err
+		}
+		set("group_id", groupID)
+		newGroupID = &groupID
+	}
+	if p.Name != nil {
+		set("song_name", *p.Name)
+	}
+	if p.ReleaseDate != nil {
+		release, err := time.Parse("02.01.2006", *p.ReleaseDate)
+		if err != nil {
+			return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+		}
+		set("release_date", release)
+	}
+	if p.Link != nil {
+		set("link", *p.Link)
+	}
+
+	if len(sets) > 0 || p.Text != nil {
+		sets = append(sets, "version = version + 1")
+		args = append(args, id)
+		q := fmt.Sprintf("UPDATE public.songs SET %s WHERE id = $%d;", strings.Join(sets, ", "), len(args))
+		r.logger.Debug("Patch", "query", q)
+		if _, err := tx.Exec(ctx, q, args...); err != nil {
+			return models.Song{}, r.duplicateError(ctx, tx, err, id, newGroupID, p.Name, "repo patch")
+		}
+	}
+
+	if p.Text != nil {
+		if err := replaceVerses(ctx, tx, id, *p.Text); err != nil {
+			return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+		}
+	}
+
+	song, err := scanSong(tx.QueryRow(
+		ctx,
+		"SELECT "+songColumns+" FROM public.songs_view WHERE id = $1;", id,
+	))
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+	}
+
+	r.logger.Debug("record patched", "id", id)
+
+	return song, nil
+}
+
+func (r *SongRepository) GetByID(ctx context.Context, id int32) (models.Song, error) {
+	song, err := scanSong(conn(ctx, r.db).QueryRow(
+		ctx,
+		"SELECT "+songColumns+" FROM public.songs_view WHERE id = $1;", id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+		}
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo get")
+	}
+
+	r.logger.Debug("record selected", "id", id)
+
+	return song, nil
+}
+
+func (r *SongRepository) SelectText(ctx context.Context, id int32) (string, error) {
 	var text string
-	if err := r.db.QueryRow(
-		`SELECT 
-		    song_text from public.songs 
-		WHERE 
+	if err := conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT
+		    song_text from public.songs_view
+		WHERE
 		    id = $1;`,
 		id,
 	).Scan(&text); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+		}
 		return "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo select")
 	}
 
@@ -126,46 +293,558 @@ func (r *SongRepository) SelectText(id int32) (string, error) {
 	return text, nil
 }
 
-func (r *SongRepository) Search(vals url.Values, pageNum, perPage int) ([]models.Song, error) {
+func (r *SongRepository) Search(ctx context.Context, sq models.SearchQuery, pageNum, perPage int) ([]models.Song, error) {
 	songs := make([]models.Song, 0)
 
-	offset := strconv.Itoa(pageNum * perPage)
-	limit := strconv.Itoa(perPage)
-	fields := []string{"group_name", "song_name", "release_date", "song_text", "link"}
-	query, err := NewQuery(fields, "SELECT * FROM public.songs", limit, offset, vals)
+	filter, err := searchFilter(sq.Criteria)
 	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+	}
+
+	keys := sq.OrderKeys()
+	if sq.After != nil {
+		keyset, err := keysetFilter(keys, sq.After)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+		}
+		filter = And(filter, keyset)
+	}
+
+	query := NewQuery("SELECT " + songColumns + " FROM public.songs_view")
+	if err := query.Where(filter); err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
 	}
+	order, err := orderTerms(keys)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+	}
+	if err := query.OrderBy(order...); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+	}
+	query.Limit(perPage, pageNum*perPage)
+
 	q := query.GetQuery()
-	// fmt.Println(q)
-	r.logger.Debug("Search", "query", q)
+	r.logger.Debug("Search", "query", q, "args", query.Args())
 
-	rows, err := r.db.Query(q)
+	rows, err := conn(ctx, r.db).Query(ctx, q, query.Args()...)
 	if err != nil {
 		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
 	}
 	defer rows.Close()
 
-	var s models.Song
 	for rows.Next() {
+		s, err := scanSong(rows)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+		}
+		songs = append(songs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+	}
+
+	r.logger.Debug("records selected", "count", len(songs))
+
+	return songs, nil
+}
+
+// Count returns the number of songs matching the search criteria.
+func (r *SongRepository) Count(ctx context.Context, sq models.SearchQuery) (int, error) {
+	filter, err := searchFilter(sq.Criteria)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo count")
+	}
+
+	query := NewQuery("SELECT count(*) FROM public.songs_view")
+	if err := query.Where(filter); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo count")
+	}
+
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, query.GetQuery(), query.Args()...).Scan(&total); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo count")
+	}
+
+	return total, nil
+}
+
+// headlineOptions configures ts_headline snippets of the lyrics search.
+const headlineOptions = `StartSel=<b>, StopSel=</b>, MaxFragments=3, MaxWords=20, MinWords=5, FragmentDelimiter=" ... "`
+
+// SearchText finds songs whose verses match the web search style query,
+// the most relevant first.
+func (r *SongRepository) SearchText(ctx context.Context, text string, pageNum, perPage int) ([]models.LyricsMatch, error) {
+	matches := make([]models.LyricsMatch, 0)
+
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`WITH q AS (
+		    SELECT websearch_to_tsquery('english', $1) AS query
+		)
+		SELECT
+		    s.id, s.group_id, s.group_name, s.song_name, s.release_date, s.song_text, s.link, s.version,
+		    m.rank, ts_headline('english', m.matched, q.query, $2)
+		FROM (
+		    SELECT
+		        v.song_id,
+		        sum(ts_rank(v.verse_tsv, q.query)) AS rank,
+		        string_agg(v.verse_text, E'\n\n' ORDER BY v.num) AS matched
+		    FROM public.verses v, q
+		    WHERE v.verse_tsv @@ q.query
+		    GROUP BY v.song_id
+		) m
+		JOIN public.songs_view s ON s.id = m.song_id
+		CROSS JOIN q
+		ORDER BY m.rank DESC, s.id
+		LIMIT $3 OFFSET $4;`,
+		text, headlineOptions, perPage, pageNum*perPage,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search text")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lm models.LyricsMatch
 		if err := rows.Scan(
-			&s.ID,
-			&s.Group,
-			&s.Name,
-			&s.ReleaseDate,
-			&s.Text,
-			&s.Link,
+			&lm.ID,
+			&lm.GroupID,
+			&lm.Group,
+			&lm.Name,
+			&lm.ReleaseDate,
+			&lm.Text,
+			&lm.Link,
+			&lm.Version,
+			&lm.Rank,
+			&lm.Headline,
 		); err != nil {
-			return nil, err
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search text")
 		}
-		songs = append(songs, s)
+		matches = append(matches, lm)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search text")
+	}
+
+	r.logger.Debug("lyrics matched", "count", len(matches))
+
+	return matches, nil
+}
 
-	if len(songs) == 0 {
-		return nil, internal.NewErrorf(internal.ErrorCodeNotFound, "no records found")
+// Suggest returns song titles where the song or the group name resemble the
+// text, typos and unfinished words allowed.
+func (r *SongRepository) Suggest(ctx context.Context, text string, limit int) ([]models.Suggestion, error) {
+	suggestions := make([]models.Suggestion, 0, limit)
+
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT
+		    s.id, g.id, g.group_name, s.song_name,
+		    greatest(word_similarity($1, s.song_name), word_similarity($1, g.group_name)) AS score
+		FROM public.songs s
+		JOIN public.groups g ON g.id = s.group_id
+		WHERE ($1 <% s.song_name OR $1 <% g.group_name) AND s.deleted_at IS NULL
+		ORDER BY score DESC, s.id
+		LIMIT $2;`,
+		text, limit,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo suggest")
 	}
+	defer rows.Close()
 
-	r.logger.Debug("records selected", "count", len(songs))
+	for rows.Next() {
+		var sg models.Suggestion
+		if err := rows.Scan(&sg.ID, &sg.GroupID, &sg.Group, &sg.Name, &sg.Score); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo suggest")
+		}
+		suggestions = append(suggestions, sg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo suggest")
+	}
+
+	r.logger.Debug("suggestions selected", "count", len(suggestions))
+
+	return suggestions, nil
+}
+
+// Trash returns deleted songs, the most recently deleted first.
+func (r *SongRepository) Trash(ctx context.Context, pageNum, perPage int) ([]models.TrashedSong, error) {
+	songs := make([]models.TrashedSong, 0)
+
+	rows, err := conn(ctx, r.db).Query(
+		ctx,
+		`SELECT
+		    `+songColumns+`, deleted_at FROM public.all_songs_view
+		WHERE
+		    deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+		LIMIT $1 OFFSET $2;`,
+		perPage, pageNum*perPage,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo trash")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ts models.TrashedSong
+		if err := rows.Scan(
+			&ts.ID,
+			&ts.GroupID,
+			&ts.Group,
+			&ts.Name,
+			&ts.ReleaseDate,
+			&ts.Text,
+			&ts.Link,
+			&ts.Version,
+			&ts.DeletedAt,
+		); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo trash")
+		}
+		songs = append(songs, ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo trash")
+	}
+
+	r.logger.Debug("trash selected", "count", len(songs))
 
 	return songs, nil
 }
+
+// Restore takes the song out of the trash.
+func (r *SongRepository) Restore(ctx context.Context, id int32) (models.Song, error) {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(
+		ctx,
+		`UPDATE
+		    public.songs
+		SET
+		    deleted_at = NULL, version = version + 1
+		WHERE
+		    id = $1 AND deleted_at IS NOT NULL;`,
+		id,
+	)
+	if err != nil {
+		return models.Song{}, r.duplicateError(ctx, tx, err, id, nil, nil, "repo restore")
+	}
+	if result.RowsAffected() != 1 {
+		return models.Song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "song with id %d is not in the trash", id)
+	}
+
+	song, err := scanSong(tx.QueryRow(
+		ctx,
+		"SELECT "+songColumns+" FROM public.songs_view WHERE id = $1;", id,
+	))
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+
+	r.logger.Debug("record restored", "id", id)
+
+	return song, nil
+}
+
+// Purge permanently removes songs deleted before the given time and returns
+// their number.
+func (r *SongRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	result, err := conn(ctx, r.db).Exec(
+		ctx,
+		"DELETE FROM public.songs WHERE deleted_at < $1;", before,
+	)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo purge")
+	}
+
+	r.logger.Debug("trash purged", "count", result.RowsAffected())
+
+	return int(result.RowsAffected()), nil
+}
+
+// searchColumns maps search fields to songs_view columns.
+var searchColumns = map[string]string{
+	"group_id":     "group_id",
+	"group_name":   "group_name",
+	"song_name":    "song_name",
+	"release_date": "release_date",
+	"song_text":    "song_text",
+	"link":         "link",
+}
+
+// orderTerms translates order keys into ORDER BY terms.
+func orderTerms(keys []models.SortKey) ([]string, error) {
+	terms := make([]string, 0, len(keys))
+	for _, k := range keys {
+		column, ok := sortColumns[k.Field]
+		if !ok {
+			return nil, fmt.Errorf("can not sort by %q", k.Field)
+		}
+
+		dir := " ASC"
+		if k.Desc {
+			dir = " DESC"
+		}
+		terms = append(terms, column+dir)
+	}
+
+	return terms, nil
+}
+
+// keysetFilter matches rows following the after values in the order of keys:
+// (k1 > a1) OR (k1 = a1 AND k2 > a2) OR ..., with < for descending keys.
+func keysetFilter(keys []models.SortKey, after []any) (Filter, error) {
+	if len(after) != len(keys) {
+		return nil, fmt.Errorf("keyset position has %d values, want %d", len(after), len(keys))
+	}
+
+	ors := make([]Filter, 0, len(keys))
+	for i, k := range keys {
+		ands := make([]Filter, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, Equal(sortColumns[keys[j].Field], after[j]))
+		}
+
+		op := ">"
+		if k.Desc {
+			op = "<"
+		}
+		column, ok := sortColumns[k.Field]
+		if !ok {
+			return nil, fmt.Errorf("can not sort by %q", k.Field)
+		}
+		ands = append(ands, Compare(column, op, after[i]))
+		ors = append(ors, And(ands...))
+	}
+
+	return Or(ors...), nil
+}
+
+// sortColumns maps sort fields to songs_view columns.
+var sortColumns = map[string]string{
+	"id":           "id",
+	"group_id":     "group_id",
+	"group_name":   "group_name",
+	"song_name":    "song_name",
+	"release_date": "release_date",
+	"link":         "link",
+}
+
+// searchFilter translates search criteria into a filter matching all of them.
+func searchFilter(criteria []models.Criterion) (Filter, error) {
+	filters := make([]Filter, 0, len(criteria))
+	for _, c := range criteria {
+		column, ok := searchColumns[c.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown search field %q", c.Field)
+		}
+		if c.Op != models.OpIn && len(c.Values) != 1 {
+			return nil, fmt.Errorf("operator %q takes one value", c.Op)
+		}
+
+		var f Filter
+		switch c.Op {
+		case models.OpEq:
+			f = Equal(column, c.Values[0])
+		case models.OpGt:
+			f = Compare(column, ">", c.Values[0])
+		case models.OpGte:
+			f = Compare(column, ">=", c.Values[0])
+		case models.OpLt:
+			f = Compare(column, "<", c.Values[0])
+		case models.OpLte:
+			f = Compare(column, "<=", c.Values[0])
+		case models.OpIn:
+			f = In(column, c.Values...)
+		case models.OpIEq, models.OpPrefix, models.OpContains, models.OpILike, models.OpSimilar:
+			v, ok := c.Values[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("operator %q takes text", c.Op)
+			}
+			switch c.Op {
+			case models.OpIEq:
+				f = EqualFold(column, v)
+			case models.OpPrefix:
+				f = Like(column, LikeEscape(v)+"%")
+			case models.OpContains:
+				f = Like(column, "%"+LikeEscape(v)+"%")
+			case models.OpILike:
+				f = ILike(column, "%"+LikeEscape(v)+"%")
+			case models.OpSimilar:
+				f = Similar(column, v)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operator %q", c.Op)
+		}
+
+		filters = append(filters, f)
+	}
+
+	return And(filters...), nil
+}
+
+// scanner is implemented by both pgx.Row and pgx.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// duplicateError translates a unique violation of the song name within its
+// group into ErrorCodeUniqueConstraints wrapping the ID of the existing song,
+// other errors get ErrorCodeUnknown. Nil group ID or name are those of the
+// song id. tx is rolled back first, it can not run queries after a failure.
+func (r *SongRepository) duplicateError(
+	ctx context.Context, tx pgx.Tx, err error, id int32, groupID *int32, name *string, op string,
+) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, op)
+	}
+	tx.Rollback(ctx)
+
+	var existing int32
+	err = conn(ctx, r.db).QueryRow(
+		ctx,
+		`SELECT
+		    id FROM public.songs
+		WHERE
+		    group_id = COALESCE($1, (SELECT group_id FROM public.songs WHERE id = $3))
+		    AND lower(song_name) = lower(COALESCE($2, (SELECT song_name FROM public.songs WHERE id = $3)))
+		    AND id <> $3 AND deleted_at IS NULL
+		LIMIT 1;`,
+		groupID, name, id,
+	).Scan(&existing)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, op)
+	}
+
+	return internal.WrapErrorf(
+		&internal.DuplicateError{ID: existing}, internal.ErrorCodeUniqueConstraints,
+		"the group already has a song with this name",
+	)
+}
+
+// lockSong locks the song row until the end of the transaction. A non-zero
+// version must match the current one.
+func lockSong(ctx context.Context, tx pgx.Tx, id, version int32) error {
+	var current int32
+	if err := tx.QueryRow(
+		ctx,
+		"SELECT version FROM public.songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;", id,
+	).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+		}
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo lock song")
+	}
+
+	if version != 0 && version != current {
+		return internal.NewErrorf(internal.ErrorCodePreconditionFailed, "song %d has version %d, not %d", id, current, version)
+	}
+
+	return nil
+}
+
+// scanSong reads a row selected with songColumns.
+func scanSong(row scanner) (models.Song, error) {
+	var s models.Song
+	err := row.Scan(
+		&s.ID,
+		&s.GroupID,
+		&s.Group,
+		&s.Name,
+		&s.ReleaseDate,
+		&s.Text,
+		&s.Link,
+		&s.Version,
+	)
+
+	return s, err
+}
+
+// resolveGroup returns the id and the name of the song group. A non-zero id
+// must refer to an existing group, otherwise the oldest group with the given
+// name is taken and created if there is none yet.
+func resolveGroup(ctx context.Context, tx pgx.Tx, id int32, name string) (int32, string, error) {
+	if id != 0 {
+		err := tx.QueryRow(
+			ctx,
+			`SELECT
+			    group_name FROM public.groups
+			WHERE
+			    id = $1;`,
+			id,
+		).Scan(&name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+		}
+		if err != nil {
+			return 0, "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "select group")
+		}
+
+		return id, name, nil
+	}
+
+	err := tx.QueryRow(
+		ctx,
+		`SELECT
+		    id FROM public.groups
+		WHERE
+		    group_name = $1
+		ORDER BY id
+		LIMIT 1;`,
+		name,
+	).Scan(&id)
+	if err == nil {
+		return id, name, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "select group")
+	}
+
+	if err := tx.QueryRow(
+		ctx,
+		`INSERT INTO public.groups (group_name) VALUES ($1) RETURNING id;`,
+		name,
+	).Scan(&id); err != nil {
+		return 0, "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "create group")
+	}
+
+	return id, name, nil
+}
+
+// insertVerses stores the song text as typed sections numerated from 1.
+func insertVerses(ctx context.Context, tx pgx.Tx, songID int32, text string) error {
+	for _, sec := range models.ParseSections(text) {
+		if _, err := tx.Exec(
+			ctx,
+			`INSERT INTO public.verses
+			    (song_id, num, kind, verse_text)
+			VALUES
+			    ($1, $2, $3, $4);`,
+			songID, sec.Num, string(sec.Kind), sec.Text,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceVerses drops the stored verses of the song and inserts new ones.
+func replaceVerses(ctx context.Context, tx pgx.Tx, songID int32, text string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM public.verses WHERE song_id = $1", songID); err != nil {
+		return err
+	}
+
+	return insertVerses(ctx, tx, songID, text)
+}
@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"music/internal"
+)
+
+// querier is implemented by both *pgxpool.Pool and pgx.Tx. Begin on a
+// transaction starts a savepoint.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// txKey is the context key of the current transaction.
+type txKey struct{}
+
+// conn returns the transaction carried by ctx, or the pool outside of
+// transactions. Repositories run all statements on it, so they join the
+// unit of work of the caller.
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// Transactor runs several repository operations as a single unit of work.
+type Transactor struct {
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewTransactor(db *pgxpool.Pool, logger *slog.Logger) *Transactor {
+	return &Transactor{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, repositories called with the
+// context passed to fn take part in it. The transaction is committed when fn
+// returns nil and rolled back when it returns an error or panics, the panic
+// is propagated. Nested calls run in a savepoint, so a failed inner unit
+// rolls back only its own changes.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := conn(ctx, t.db).Begin(ctx)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "begin transaction")
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			t.rollback(tx)
+			panic(p)
+		}
+		if err != nil {
+			t.rollback(tx)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "commit transaction")
+	}
+
+	return nil
+}
+
+// rollback does not use the request context, it may be already cancelled.
+func (t *Transactor) rollback(tx pgx.Tx) {
+	if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		t.logger.Error("transaction rollback", "error", err)
+	}
+}
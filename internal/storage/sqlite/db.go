@@ -0,0 +1,98 @@
+// Package sqlite keeps data in an embedded SQLite database file, for
+// single-node setups without a PostgreSQL server.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	drv "modernc.org/sqlite"
+
+	migrations "music/db"
+	"music/internal/storage/trgm"
+)
+
+// Scheme is the DB_URL scheme of SQLite databases, e.g. sqlite://music.db
+// for a file in the working directory or sqlite:///var/lib/music.db.
+const Scheme = "sqlite"
+
+// pragmas are applied to every connection: references are checked and
+// writers from other processes, e.g. purge, are waited for.
+var pragmas = []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}
+
+func init() {
+	// PostgreSQL functions used by queries of the repositories
+	text := func(fn func(a, b string) float32) func(*drv.FunctionContext, []driver.Value) (driver.Value, error) {
+		return func(_ *drv.FunctionContext, args []driver.Value) (driver.Value, error) {
+			a, _ := args[0].(string)
+			b, _ := args[1].(string)
+			return float64(fn(a, b)), nil
+		}
+	}
+	drv.MustRegisterDeterministicScalarFunction("similarity", 2, text(trgm.Similarity))
+	drv.MustRegisterDeterministicScalarFunction("word_similarity", 2, text(trgm.WordSimilarity))
+	// lower of SQLite folds ASCII letters only
+	drv.MustRegisterDeterministicScalarFunction(
+		"fold", 1,
+		func(_ *drv.FunctionContext, args []driver.Value) (driver.Value, error) {
+			s, _ := args[0].(string)
+			return strings.ToLower(s), nil
+		},
+	)
+}
+
+// Open opens the database file named by the sqlite:// DB_URL and checks it
+// is usable. SQLite has a single writer, so the pool keeps one connection:
+// statements queue instead of failing on a locked database.
+func Open(ctx context.Context, dbURL string) (*sql.DB, error) {
+	path, ok := strings.CutPrefix(dbURL, Scheme+"://")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid sqlite url %q", dbURL)
+	}
+
+	q := url.Values{"_pragma": pragmas, "_txlock": {"immediate"}}
+	db, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Migrate applies all pending migrations embedded from db/sqlite_migrations.
+// The migrator is not closed: it would close db, and it holds no connection
+// of its own.
+func Migrate(db *sql.DB) error {
+	src, err := iofs.New(migrations.SQLiteMigrations, "sqlite_migrations")
+	if err != nil {
+		return err
+	}
+	instance, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "music", instance)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
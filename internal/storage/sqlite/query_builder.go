@@ -0,0 +1,242 @@
+package sqlite
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"music/internal/storage/trgm"
+)
+
+// identifier matches column names allowed in filters and ORDER BY terms.
+var identifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// Query builds a parameterized statement. Values never get into the SQL text,
+// they are collected in args and referenced with ?n placeholders.
+type Query struct {
+	builder strings.Builder
+	args    []any
+}
+
+// NewQuery starts a query with the given statement head, e.g. SELECT ... FROM ...
+func NewQuery(s string) *Query {
+	q := &Query{}
+	q.builder.WriteString(s)
+
+	return q
+}
+
+// Arg adds a query argument and returns its placeholder. Times are dates
+// here and are stored as text.
+func (q *Query) Arg(v any) string {
+	if t, ok := v.(time.Time); ok {
+		v = t.Format(time.DateOnly)
+	}
+	q.args = append(q.args, v)
+
+	return "?" + strconv.Itoa(len(q.args))
+}
+
+// Where appends the WHERE clause, an empty filter adds nothing.
+func (q *Query) Where(f Filter) error {
+	if f == nil {
+		return nil
+	}
+
+	cond, err := f.render(q)
+	if err != nil {
+		return err
+	}
+	if cond != "" {
+		q.builder.WriteString(" WHERE ")
+		q.builder.WriteString(cond)
+	}
+
+	return nil
+}
+
+// OrderBy appends the ORDER BY clause, terms are column names optionally
+// followed by ASC or DESC.
+func (q *Query) OrderBy(terms ...string) error {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	for _, t := range terms {
+		col, dir, _ := strings.Cut(t, " ")
+		if !identifier.MatchString(col) {
+			return fmt.Errorf("invalid order column %q", col)
+		}
+		if dir != "" && dir != "ASC" && dir != "DESC" {
+			return fmt.Errorf("invalid order direction %q", dir)
+		}
+	}
+
+	q.builder.WriteString(" ORDER BY ")
+	q.builder.WriteString(strings.Join(terms, ", "))
+
+	return nil
+}
+
+// Limit appends LIMIT and OFFSET clauses.
+func (q *Query) Limit(limit, offset int) {
+	q.builder.WriteString(" LIMIT ")
+	q.builder.WriteString(q.Arg(limit))
+	q.builder.WriteString(" OFFSET ")
+	q.builder.WriteString(q.Arg(offset))
+}
+
+func (q *Query) GetQuery() string {
+	return q.builder.String() + ";"
+}
+
+func (q *Query) Args() []any {
+	return q.args
+}
+
+// Filter is a node of a WHERE clause tree.
+type Filter interface {
+	// render returns the SQL condition, its values are added to q args.
+	render(q *Query) (string, error)
+}
+
+type condition struct {
+	column string
+	op     string
+	val    any
+}
+
+// Equal matches rows where column equals val.
+func Equal(column string, val any) Filter {
+	return condition{column: column, op: "=", val: val}
+}
+
+// Compare matches rows where column relates to val by op, one of
+// =, <>, <, <=, >, >=.
+func Compare(column, op string, val any) Filter {
+	return condition{column: column, op: op, val: val}
+}
+
+// Glob matches column against a case-sensitive GLOB pattern, see GlobEscape.
+func Glob(column, pattern string) Filter {
+	return condition{column: column, op: "GLOB", val: pattern}
+}
+
+// ILike matches column against a LIKE pattern ignoring case, see LikeEscape.
+func ILike(column, pattern string) Filter {
+	return condition{column: column, op: "ILIKE", val: pattern}
+}
+
+// Similar matches rows where column is similar to val by trigrams, see trgm.
+func Similar(column, val string) Filter {
+	return condition{column: column, op: "%", val: val}
+}
+
+// EqualFold matches rows where column equals val ignoring case.
+func EqualFold(column, val string) Filter {
+	return condition{column: column, op: "IEQ", val: val}
+}
+
+// LikeEscape escapes LIKE wildcards in s so it matches literally.
+func LikeEscape(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GlobEscape escapes GLOB wildcards in s so it matches literally.
+func GlobEscape(s string) string {
+	return globEscaper.Replace(s)
+}
+
+var globEscaper = strings.NewReplacer(`*`, `[*]`, `?`, `[?]`, `[`, `[[]`)
+
+func (c condition) render(q *Query) (string, error) {
+	if !identifier.MatchString(c.column) {
+		return "", fmt.Errorf("invalid filter column %q", c.column)
+	}
+
+	switch c.op {
+	case "=", "<>", "<", "<=", ">", ">=", "GLOB":
+		return c.column + " " + c.op + " " + q.Arg(c.val), nil
+	case "IEQ":
+		return "fold(" + c.column + ") = fold(" + q.Arg(c.val) + ")", nil
+	case "ILIKE":
+		return "fold(" + c.column + ") LIKE fold(" + q.Arg(c.val) + `) ESCAPE '\'`, nil
+	case "%":
+		return "similarity(" + c.column + ", " + q.Arg(c.val) + ") >= " +
+			strconv.FormatFloat(trgm.SimilarityThreshold, 'f', -1, 64), nil
+	}
+
+	return "", fmt.Errorf("invalid filter operator %q", c.op)
+}
+
+type in struct {
+	column string
+	vals   []any
+}
+
+// In matches rows where column equals any of vals.
+func In(column string, vals ...any) Filter {
+	return in{column: column, vals: vals}
+}
+
+// render matches nothing for an empty list.
+func (f in) render(q *Query) (string, error) {
+	if !identifier.MatchString(f.column) {
+		return "", fmt.Errorf("invalid filter column %q", f.column)
+	}
+	if len(f.vals) == 0 {
+		return "1 = 0", nil
+	}
+
+	placeholders := make([]string, len(f.vals))
+	for i, v := range f.vals {
+		placeholders[i] = q.Arg(v)
+	}
+
+	return f.column + " IN (" + strings.Join(placeholders, ", ") + ")", nil
+}
+
+type group struct {
+	op      string
+	filters []Filter
+}
+
+// And matches rows satisfying all filters.
+func And(filters ...Filter) Filter {
+	return group{op: " AND ", filters: filters}
+}
+
+// Or matches rows satisfying any of filters.
+func Or(filters ...Filter) Filter {
+	return group{op: " OR ", filters: filters}
+}
+
+// render skips empty members, a group without members renders empty.
+func (g group) render(q *Query) (string, error) {
+	parts := make([]string, 0, len(g.filters))
+	for _, f := range g.filters {
+		if f == nil {
+			continue
+		}
+		p, err := f.render(q)
+		if err != nil {
+			return "", err
+		}
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return "", nil
+	case 1:
+		return parts[0], nil
+	}
+
+	return "(" + strings.Join(parts, g.op) + ")", nil
+}
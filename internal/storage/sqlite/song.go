@@ -0,0 +1,949 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+	"music/internal/storage/trgm"
+	"music/internal/storage/websearch"
+)
+
+// songColumns lists songs_view columns in the order expected by scanSong.
+const songColumns = "id, group_id, group_name, song_name, release_date, song_text, link, version"
+
+// now is the current UTC time in timestampLayout, for deleted_at and
+// created_at columns.
+const now = "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+
+// timestampLayout is the text form of timestamps, it sorts in time order.
+const timestampLayout = "2006-01-02 15:04:05.000"
+
+type SongRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewSongRepo(db *sql.DB, logger *slog.Logger) *SongRepository {
+	return &SongRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SongRepository) Create(ctx context.Context, p m.CreateParams) (models.Song, error) {
+	var id int32
+	release, err := time.Parse("02.01.2006", p.ReleaseDate)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+	}
+
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
+	}
+	defer tx.Rollback()
+
+	groupID, group, err := resolveGroup(ctx, tx, p.GroupID, p.Group)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	if err := checkUnique(ctx, tx, 0, &groupID, &p.Name); err != nil {
+		return models.Song{}, err
+	}
+
+	if err := tx.QueryRowContext(
+		ctx,
+		`INSERT INTO songs
+		    (group_id, song_name, release_date, link)
+		VALUES
+		    (?1, ?2, ?3, ?4)
+		RETURNING id;`,
+		groupID, p.Name, release.Format(time.DateOnly), p.Link,
+	).Scan(&id); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
+	}
+
+	if err := insertVerses(ctx, tx, id, p.Text); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo create")
+	}
+
+	r.logger.Debug("record created", "id", id)
+
+	return models.Song{
+		ID:          id,
+		GroupID:     groupID,
+		Group:       group,
+		Name:        p.Name,
+		ReleaseDate: release,
+		Text:        models.NormalizeLyrics(p.Text),
+		Link:        p.Link,
+		Version:     1,
+	}, nil
+}
+
+// Delete moves the song to the trash, a non-zero version must match the
+// current one.
+func (r *SongRepository) Delete(ctx context.Context, id, version int32) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete")
+	}
+	defer tx.Rollback()
+
+	if err := checkVersion(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE songs SET deleted_at = "+now+", version = version + 1 WHERE id = ?1;", id,
+	); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo delete")
+	}
+
+	r.logger.Debug("record deleted", "id", id)
+	return nil
+}
+
+// Update replaces the song, a non-zero version must match the current one.
+func (r *SongRepository) Update(ctx context.Context, id, version int32, p m.UpdateParams) (models.Song, error) {
+	release, err := time.Parse("02.01.2006", p.ReleaseDate)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+	}
+
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
+	}
+	defer tx.Rollback()
+
+	if err := checkVersion(ctx, tx, id, version); err != nil {
+		return models.Song{}, err
+	}
+
+	groupID, group, err := resolveGroup(ctx, tx, p.GroupID, p.Group)
+	if err != nil {
+		return models.Song{}, err
+	}
+
+	if err := checkUnique(ctx, tx, id, &groupID, &p.Name); err != nil {
+		return models.Song{}, err
+	}
+
+	if err := tx.QueryRowContext(
+		ctx,
+		`UPDATE
+		    songs
+		SET
+		    group_id = ?1, song_name = ?2, release_date = ?3, link = ?4, version = version + 1
+		WHERE
+		   id = ?5
+		RETURNING version;`,
+		groupID, p.Name, release.Format(time.DateOnly), p.Link, id,
+	).Scan(&version); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
+	}
+
+	if err := replaceVerses(ctx, tx, id, p.Text); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo update")
+	}
+
+	r.logger.Debug("record updated", "id", id)
+
+	return models.Song{
+		ID:          id,
+		GroupID:     groupID,
+		Group:       group,
+		Name:        p.Name,
+		ReleaseDate: release,
+		Text:        models.NormalizeLyrics(p.Text),
+		Link:        p.Link,
+		Version:     version,
+	}, nil
+}
+
+// Patch changes the given song fields, a non-zero version must match the
+// current one.
+func (r *SongRepository) Patch(ctx context.Context, id, version int32, p m.PatchParams) (models.Song, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+	}
+	defer tx.Rollback()
+
+	// It also tells a missing song from an empty patch
+	if err := checkVersion(ctx, tx, id, version); err != nil {
+		return models.Song{}, err
+	}
+
+	sets := make([]string, 0, 4)
+	args := make([]any, 0, 5)
+	set := func(column string, val any) {
+		args = append(args, val)
+		sets = append(sets, fmt.Sprintf("%s = ?%d", column, len(args)))
+	}
+
+	var newGroupID *int32
+	if p.GroupID != nil || p.Group != nil {
+		var groupID int32
+		var group string
+		if p.GroupID != nil {
+			groupID = *p.GroupID
+		}
+		if p.Group != nil {
+			group = *p.Group
+		}
+		groupID, _, err = resolveGroup(ctx, tx, groupID, group)
+		if err != nil {
+			return models.Song{}, err
+		}
+		set("group_id", groupID)
+		newGroupID = &groupID
+	}
+	if p.Name != nil {
+		set("song_name", *p.Name)
+	}
+	if p.ReleaseDate != nil {
+		release, err := time.Parse("02.01.2006", *p.ReleaseDate)
+		if err != nil {
+			return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "invalid date")
+		}
+		set("release_date", release.Format(time.DateOnly))
+	}
+	if p.Link != nil {
+		set("link", *p.Link)
+	}
+
+	if newGroupID != nil || p.Name != nil {
+		if err := checkUnique(ctx, tx, id, newGroupID, p.Name); err != nil {
+			return models.Song{}, err
+		}
+	}
+
+	if len(sets) > 0 || p.Text != nil {
+		sets = append(sets, "version = version + 1")
+		args = append(args, id)
+		q := fmt.Sprintf("UPDATE songs SET %s WHERE id = ?%d;", strings.Join(sets, ", "), len(args))
+		r.logger.Debug("Patch", "query", q)
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+		}
+	}
+
+	if p.Text != nil {
+		if err := replaceVerses(ctx, tx, id, *p.Text); err != nil {
+			return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+		}
+	}
+
+	song, err := scanSong(tx.QueryRowContext(
+		ctx,
+		"SELECT "+songColumns+" FROM songs_view WHERE id = ?1;", id,
+	))
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo patch")
+	}
+
+	r.logger.Debug("record patched", "id", id)
+
+	return song, nil
+}
+
+func (r *SongRepository) GetByID(ctx context.Context, id int32) (models.Song, error) {
+	song, err := scanSong(conn(ctx, r.db).QueryRowContext(
+		ctx,
+		"SELECT "+songColumns+" FROM songs_view WHERE id = ?1;", id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+		}
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo get")
+	}
+
+	r.logger.Debug("record selected", "id", id)
+
+	return song, nil
+}
+
+func (r *SongRepository) SelectText(ctx context.Context, id int32) (string, error) {
+	var text string
+	if err := conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT
+		    song_text from songs_view
+		WHERE
+		    id = ?1;`,
+		id,
+	).Scan(&text); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+		}
+		return "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo select")
+	}
+
+	r.logger.Debug("text selected", "id", id)
+
+	return text, nil
+}
+
+func (r *SongRepository) Search(ctx context.Context, sq models.SearchQuery, pageNum, perPage int) ([]models.Song, error) {
+	songs := make([]models.Song, 0)
+
+	filter, err := searchFilter(sq.Criteria)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+	}
+
+	keys := sq.OrderKeys()
+	if sq.After != nil {
+		keyset, err := keysetFilter(keys, sq.After)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+		}
+		filter = And(filter, keyset)
+	}
+
+	query := NewQuery("SELECT " + songColumns + " FROM songs_view")
+	if err := query.Where(filter); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+	}
+	order, err := orderTerms(keys)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo search")
+	}
+	if err := query.OrderBy(order...); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+	}
+	query.Limit(perPage, pageNum*perPage)
+
+	q := query.GetQuery()
+	r.logger.Debug("Search", "query", q, "args", query.Args())
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, query.Args()...)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSong(rows)
+		if err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+		}
+		songs = append(songs, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search")
+	}
+
+	r.logger.Debug("records selected", "count", len(songs))
+
+	return songs, nil
+}
+
+// Count returns the number of songs matching the search criteria.
+func (r *SongRepository) Count(ctx context.Context, sq models.SearchQuery) (int, error) {
+	filter, err := searchFilter(sq.Criteria)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeInvalidArgument, "repo count")
+	}
+
+	query := NewQuery("SELECT count(*) FROM songs_view")
+	if err := query.Where(filter); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo count")
+	}
+
+	var total int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query.GetQuery(), query.Args()...).Scan(&total); err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo count")
+	}
+
+	return total, nil
+}
+
+// SearchText finds songs whose verses match the web search style query,
+// the most relevant first. Matched verses make the headline with the found
+// words wrapped in <b></b>. FTS5 ranks and highlights only in the query on
+// its table, so hits are materialized before grouping.
+func (r *SongRepository) SearchText(ctx context.Context, text string, pageNum, perPage int) ([]models.LyricsMatch, error) {
+	matches := make([]models.LyricsMatch, 0)
+
+	match := ftsQuery(text)
+	if match == "" {
+		return matches, nil
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`WITH hits AS MATERIALIZED (
+		    SELECT
+		        v.song_id,
+		        v.num,
+		        -bm25(verses_fts) AS rank,
+		        highlight(verses_fts, 0, '<b>', '</b>') AS headline
+		    FROM verses_fts
+		    JOIN verses v ON v.id = verses_fts.rowid
+		    WHERE verses_fts MATCH ?1
+		), m AS (
+		    SELECT
+		        song_id,
+		        sum(rank) AS rank,
+		        group_concat(headline, ' ... ' ORDER BY num) AS headline
+		    FROM hits
+		    GROUP BY song_id
+		)
+		SELECT
+		    s.id, s.group_id, s.group_name, s.song_name, s.release_date, s.song_text, s.link, s.version,
+		    m.rank, m.headline
+		FROM m
+		JOIN songs_view s ON s.id = m.song_id
+		ORDER BY m.rank DESC, s.id
+		LIMIT ?2 OFFSET ?3;`,
+		match, perPage, pageNum*perPage,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search text")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lm models.LyricsMatch
+		if err := rows.Scan(
+			&lm.ID,
+			&lm.GroupID,
+			&lm.Group,
+			&lm.Name,
+			timeValue{&lm.ReleaseDate},
+			&lm.Text,
+			&lm.Link,
+			&lm.Version,
+			&lm.Rank,
+			&lm.Headline,
+		); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search text")
+		}
+		matches = append(matches, lm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo search text")
+	}
+
+	r.logger.Debug("lyrics matched", "count", len(matches))
+
+	return matches, nil
+}
+
+// ftsQuery translates a web search style query, see websearch.Parse, into
+// FTS5 syntax. Empty without words to match.
+func ftsQuery(text string) string {
+	q := websearch.Parse(text)
+	if q.Empty() {
+		return ""
+	}
+
+	phrase := func(p websearch.Phrase) string {
+		return `"` + strings.Join(p, " ") + `"`
+	}
+	terms := make([]string, len(q.All))
+	for i, group := range q.All {
+		alts := make([]string, len(group))
+		for k, p := range group {
+			alts[k] = phrase(p)
+		}
+		terms[i] = strings.Join(alts, " OR ")
+		if len(alts) > 1 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+
+	match := strings.Join(terms, " AND ")
+	for _, p := range q.Not {
+		match = "(" + match + ") NOT " + phrase(p)
+	}
+
+	return match
+}
+
+// Suggest returns song titles where the song or the group name resemble the
+// text, typos and unfinished words allowed.
+func (r *SongRepository) Suggest(ctx context.Context, text string, limit int) ([]models.Suggestion, error) {
+	suggestions := make([]models.Suggestion, 0, limit)
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT
+		    id, group_id, group_name, song_name, score
+		FROM (
+		    SELECT
+		        s.id, g.id AS group_id, g.group_name, s.song_name,
+		        max(word_similarity(?1, s.song_name), word_similarity(?1, g.group_name)) AS score
+		    FROM songs s
+		    JOIN groups g ON g.id = s.group_id
+		    WHERE s.deleted_at IS NULL
+		)
+		WHERE score >= ?2
+		ORDER BY score DESC, id
+		LIMIT ?3;`,
+		text, trgm.WordSimilarityThreshold, limit,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo suggest")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sg models.Suggestion
+		if err := rows.Scan(&sg.ID, &sg.GroupID, &sg.Group, &sg.Name, &sg.Score); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo suggest")
+		}
+		suggestions = append(suggestions, sg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo suggest")
+	}
+
+	r.logger.Debug("suggestions selected", "count", len(suggestions))
+
+	return suggestions, nil
+}
+
+// Trash returns deleted songs, the most recently deleted first.
+func (r *SongRepository) Trash(ctx context.Context, pageNum, perPage int) ([]models.TrashedSong, error) {
+	songs := make([]models.TrashedSong, 0)
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT
+		    `+songColumns+`, deleted_at FROM all_songs_view
+		WHERE
+		    deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+		LIMIT ?1 OFFSET ?2;`,
+		perPage, pageNum*perPage,
+	)
+	if err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo trash")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ts models.TrashedSong
+		if err := rows.Scan(
+			&ts.ID,
+			&ts.GroupID,
+			&ts.Group,
+			&ts.Name,
+			timeValue{&ts.ReleaseDate},
+			&ts.Text,
+			&ts.Link,
+			&ts.Version,
+			timeValue{&ts.DeletedAt},
+		); err != nil {
+			return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo trash")
+		}
+		songs = append(songs, ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo trash")
+	}
+
+	r.logger.Debug("trash selected", "count", len(songs))
+
+	return songs, nil
+}
+
+// Restore takes the song out of the trash.
+func (r *SongRepository) Restore(ctx context.Context, id int32) (models.Song, error) {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+	defer tx.Rollback()
+
+	var trashed bool
+	if err := tx.QueryRowContext(
+		ctx,
+		"SELECT deleted_at IS NOT NULL FROM songs WHERE id = ?1;", id,
+	).Scan(&trashed); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+	if !trashed {
+		return models.Song{}, internal.NewErrorf(internal.ErrorCodeNotFound, "song with id %d is not in the trash", id)
+	}
+
+	if err := checkUnique(ctx, tx, id, nil, nil); err != nil {
+		return models.Song{}, err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE
+		    songs
+		SET
+		    deleted_at = NULL, version = version + 1
+		WHERE
+		    id = ?1;`,
+		id,
+	); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+
+	song, err := scanSong(tx.QueryRowContext(
+		ctx,
+		"SELECT "+songColumns+" FROM songs_view WHERE id = ?1;", id,
+	))
+	if err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Song{}, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo restore")
+	}
+
+	r.logger.Debug("record restored", "id", id)
+
+	return song, nil
+}
+
+// Purge permanently removes songs deleted before the given time and returns
+// their number.
+func (r *SongRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		"DELETE FROM songs WHERE deleted_at < ?1;", before.UTC().Format(timestampLayout),
+	)
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo purge")
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo purge")
+	}
+
+	r.logger.Debug("trash purged", "count", n)
+
+	return int(n), nil
+}
+
+// searchColumns maps search fields to songs_view columns.
+var searchColumns = map[string]string{
+	"group_id":     "group_id",
+	"group_name":   "group_name",
+	"song_name":    "song_name",
+	"release_date": "release_date",
+	"song_text":    "song_text",
+	"link":         "link",
+}
+
+// orderTerms translates order keys into ORDER BY terms.
+func orderTerms(keys []models.SortKey) ([]string, error) {
+	terms := make([]string, 0, len(keys))
+	for _, k := range keys {
+		column, ok := sortColumns[k.Field]
+		if !ok {
+			return nil, fmt.Errorf("can not sort by %q", k.Field)
+		}
+
+		dir := " ASC"
+		if k.Desc {
+			dir = " DESC"
+		}
+		terms = append(terms, column+dir)
+	}
+
+	return terms, nil
+}
+
+// keysetFilter matches rows following the after values in the order of keys:
+// (k1 > a1) OR (k1 = a1 AND k2 > a2) OR ..., with < for descending keys.
+func keysetFilter(keys []models.SortKey, after []any) (Filter, error) {
+	if len(after) != len(keys) {
+		return nil, fmt.Errorf("keyset position has %d values, want %d", len(after), len(keys))
+	}
+
+	ors := make([]Filter, 0, len(keys))
+	for i, k := range keys {
+		ands := make([]Filter, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, Equal(sortColumns[keys[j].Field], after[j]))
+		}
+
+		op := ">"
+		if k.Desc {
+			op = "<"
+		}
+		column, ok := sortColumns[k.Field]
+		if !ok {
+			return nil, fmt.Errorf("can not sort by %q", k.Field)
+		}
+		ands = append(ands, Compare(column, op, after[i]))
+		ors = append(ors, And(ands...))
+	}
+
+	return Or(ors...), nil
+}
+
+// sortColumns maps sort fields to songs_view columns.
+var sortColumns = map[string]string{
+	"id":           "id",
+	"group_id":     "group_id",
+	"group_name":   "group_name",
+	"song_name":    "song_name",
+	"release_date": "release_date",
+	"link":         "link",
+}
+
+// searchFilter translates search criteria into a filter matching all of them.
+func searchFilter(criteria []models.Criterion) (Filter, error) {
+	filters := make([]Filter, 0, len(criteria))
+	for _, c := range criteria {
+		column, ok := searchColumns[c.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown search field %q", c.Field)
+		}
+		if c.Op != models.OpIn && len(c.Values) != 1 {
+			return nil, fmt.Errorf("operator %q takes one value", c.Op)
+		}
+
+		var f Filter
+		switch c.Op {
+		case models.OpEq:
+			f = Equal(column, c.Values[0])
+		case models.OpGt:
+			f = Compare(column, ">", c.Values[0])
+		case models.OpGte:
+			f = Compare(column, ">=", c.Values[0])
+		case models.OpLt:
+			f = Compare(column, "<", c.Values[0])
+		case models.OpLte:
+			f = Compare(column, "<=", c.Values[0])
+		case models.OpIn:
+			f = In(column, c.Values...)
+		case models.OpIEq, models.OpPrefix, models.OpContains, models.OpILike, models.OpSimilar:
+			v, ok := c.Values[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("operator %q takes text", c.Op)
+			}
+			switch c.Op {
+			case models.OpIEq:
+				f = EqualFold(column, v)
+			case models.OpPrefix:
+				f = Glob(column, GlobEscape(v)+"*")
+			case models.OpContains:
+				f = Glob(column, "*"+GlobEscape(v)+"*")
+			case models.OpILike:
+				f = ILike(column, "%"+LikeEscape(v)+"%")
+			case models.OpSimilar:
+				f = Similar(column, v)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operator %q", c.Op)
+		}
+
+		filters = append(filters, f)
+	}
+
+	return And(filters...), nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// timeValue scans dates and timestamps, the driver returns them as
+// time.Time or, out of typed columns, as text.
+type timeValue struct {
+	t *time.Time
+}
+
+func (v timeValue) Scan(src any) error {
+	switch s := src.(type) {
+	case time.Time:
+		*v.t = s
+		return nil
+	case string:
+		for _, layout := range []string{time.DateOnly, timestampLayout} {
+			if t, err := time.Parse(layout, s); err == nil {
+				*v.t = t
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("can not scan %T %v as time", src, src)
+}
+
+// checkUnique makes sure no other song out of the trash has the name in
+// the group, names are compared ignoring case. Nil group ID or name are
+// those of the song id. The duplicate gets ErrorCodeUniqueConstraints
+// wrapping its ID.
+func checkUnique(ctx context.Context, q querier, id int32, groupID *int32, name *string) error {
+	var existing int32
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT
+		    id FROM songs
+		WHERE
+		    group_id = COALESCE(?1, (SELECT group_id FROM songs WHERE id = ?3))
+		    AND fold(song_name) = fold(COALESCE(?2, (SELECT song_name FROM songs WHERE id = ?3)))
+		    AND id <> ?3 AND deleted_at IS NULL
+		LIMIT 1;`,
+		groupID, name, id,
+	).Scan(&existing)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo check unique")
+	}
+
+	return internal.WrapErrorf(
+		&internal.DuplicateError{ID: existing}, internal.ErrorCodeUniqueConstraints,
+		"the group already has a song with this name",
+	)
+}
+
+// checkVersion makes sure the song is out of the trash, a non-zero version
+// must match the current one. Transactions take the write lock on begin,
+// the row can not change until the end of the transaction.
+func checkVersion(ctx context.Context, q querier, id, version int32) error {
+	var current int32
+	if err := q.QueryRowContext(
+		ctx,
+		"SELECT version FROM songs WHERE id = ?1 AND deleted_at IS NULL;", id,
+	).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return internal.NewErrorf(internal.ErrorCodeNotFound, "resourse with id %d not found", id)
+		}
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "repo check version")
+	}
+
+	if version != 0 && version != current {
+		return internal.NewErrorf(internal.ErrorCodePreconditionFailed, "song %d has version %d, not %d", id, current, version)
+	}
+
+	return nil
+}
+
+// scanSong reads a row selected with songColumns.
+func scanSong(row scanner) (models.Song, error) {
+	var s models.Song
+	err := row.Scan(
+		&s.ID,
+		&s.GroupID,
+		&s.Group,
+		&s.Name,
+		timeValue{&s.ReleaseDate},
+		&s.Text,
+		&s.Link,
+		&s.Version,
+	)
+
+	return s, err
+}
+
+// resolveGroup returns the id and the name of the song group. A non-zero id
+// must refer to an existing group, otherwise the oldest group with the given
+// name is taken and created if there is none yet.
+func resolveGroup(ctx context.Context, q querier, id int32, name string) (int32, string, error) {
+	if id != 0 {
+		err := q.QueryRowContext(
+			ctx,
+			`SELECT
+			    group_name FROM groups
+			WHERE
+			    id = ?1;`,
+			id,
+		).Scan(&name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", internal.NewErrorf(internal.ErrorCodeNotFound, "group with id %d not found", id)
+		}
+		if err != nil {
+			return 0, "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "select group")
+		}
+
+		return id, name, nil
+	}
+
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT
+		    id FROM groups
+		WHERE
+		    group_name = ?1
+		ORDER BY id
+		LIMIT 1;`,
+		name,
+	).Scan(&id)
+	if err == nil {
+		return id, name, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "select group")
+	}
+
+	if err := q.QueryRowContext(
+		ctx,
+		`INSERT INTO groups (group_name) VALUES (?1) RETURNING id;`,
+		name,
+	).Scan(&id); err != nil {
+		return 0, "", internal.WrapErrorf(err, internal.ErrorCodeUnknown, "create group")
+	}
+
+	return id, name, nil
+}
+
+// insertVerses stores the song text as typed sections numerated from 1.
+func insertVerses(ctx context.Context, q querier, songID int32, text string) error {
+	for _, sec := range models.ParseSections(text) {
+		if _, err := q.ExecContext(
+			ctx,
+			`INSERT INTO verses
+			    (song_id, num, kind, verse_text)
+			VALUES
+			    (?1, ?2, ?3, ?4);`,
+			songID, sec.Num, string(sec.Kind), sec.Text,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceVerses drops the stored verses of the song and inserts new ones.
+func replaceVerses(ctx context.Context, q querier, songID int32, text string) error {
+	if _, err := q.ExecContext(ctx, "DELETE FROM verses WHERE song_id = ?1", songID); err != nil {
+		return err
+	}
+
+	return insertVerses(ctx, q, songID, text)
+}
@@ -0,0 +1,36 @@
+package sqlite_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"music/internal/storage"
+	"music/internal/storage/sqlite"
+	"music/internal/storage/storagetest"
+)
+
+func TestRepositories(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	storagetest.Run(t, func(t *testing.T) *storage.Repositories {
+		db, err := sqlite.Open(context.Background(), sqlite.Scheme+"://"+filepath.Join(t.TempDir(), "music.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sqlite.Migrate(db); err != nil {
+			db.Close()
+			t.Fatal(err)
+		}
+
+		return &storage.Repositories{
+			Songs:     sqlite.NewSongRepo(db, logger),
+			Groups:    sqlite.NewGroupRepo(db, logger),
+			Revisions: sqlite.NewRevisionRepo(db, logger),
+			Tx:        sqlite.NewTransactor(db, logger),
+			Close:     func() { db.Close() },
+		}
+	})
+}
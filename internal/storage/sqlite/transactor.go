@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"music/internal"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey is the context key of the current transaction.
+type txKey struct{}
+
+// conn returns the transaction carried by ctx, or the database outside of
+// transactions. Repositories run all statements on it, so they join the
+// unit of work of the caller.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// savepoints numbers savepoint names.
+var savepoints atomic.Int64
+
+// tx is a transaction or, within one, a savepoint. Commit and Rollback may
+// be called several times, only the first call has effect.
+type tx struct {
+	*sql.Tx
+	savepoint string
+	done      bool
+}
+
+// begin starts a transaction, or a savepoint when ctx already carries one.
+func begin(ctx context.Context, db *sql.DB) (*tx, error) {
+	if outer, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		name := fmt.Sprintf("sp%d", savepoints.Add(1))
+		if _, err := outer.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+			return nil, err
+		}
+
+		return &tx{Tx: outer, savepoint: name}, nil
+	}
+
+	t, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx{Tx: t}, nil
+}
+
+func (t *tx) Commit() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	if t.savepoint != "" {
+		_, err := t.Tx.Exec("RELEASE " + t.savepoint)
+		return err
+	}
+
+	return t.Tx.Commit()
+}
+
+func (t *tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	if t.savepoint != "" {
+		if _, err := t.Tx.Exec("ROLLBACK TO " + t.savepoint); err != nil {
+			return err
+		}
+		_, err := t.Tx.Exec("RELEASE " + t.savepoint)
+		return err
+	}
+
+	return t.Tx.Rollback()
+}
+
+// Transactor runs several repository operations as a single unit of work.
+type Transactor struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+func NewTransactor(db *sql.DB, logger *slog.Logger) *Transactor {
+	return &Transactor{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithinTransaction runs fn in a transaction, repositories called with the
+// context passed to fn take part in it. The transaction is committed when fn
+// returns nil and rolled back when it returns an error or panics, the panic
+// is propagated. Nested calls run in a savepoint, so a failed inner unit
+// rolls back only its own changes.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	tx, err := begin(ctx, t.db)
+	if err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "begin transaction")
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			t.rollback(tx)
+			panic(p)
+		}
+		if err != nil {
+			t.rollback(tx)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx.Tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return internal.WrapErrorf(err, internal.ErrorCodeUnknown, "commit transaction")
+	}
+
+	return nil
+}
+
+func (t *Transactor) rollback(tx *tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		t.logger.Error("transaction rollback", "error", err)
+	}
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"music/internal/app/service"
+	"music/internal/config"
+	"music/internal/storage/memory"
+	"music/internal/storage/postgresql"
+	"music/internal/storage/sqlite"
+)
+
+// Storage kinds selected by the STORAGE config option.
+const (
+	// Database keeps data in the database at DB_URL: PostgreSQL or, with the
+	// sqlite:// scheme, an SQLite file
+	Database = "db"
+	// Memory keeps data in memory until the process exits, for tests and demos
+	Memory = "memory"
+)
+
+// Repositories are the repositories used by services, all backed by the
+// same storage.
+type Repositories struct {
+	Songs     service.SongRepository
+	Groups    service.GroupRepository
+	Revisions service.RevisionRepository
+	Tx        service.Transactor
+	// Close releases the storage
+	Close func()
+}
+
+// Open opens the storage chosen by cfg, the database schema is migrated up.
+func Open(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Repositories, error) {
+	switch cfg.Storage {
+	case Database:
+		if strings.HasPrefix(cfg.DbUrl, sqlite.Scheme+"://") {
+			return openSQLite(ctx, cfg, logger)
+		}
+
+		db, err := postgresql.NewPool(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("connecting db: %w", err)
+		}
+		if err := postgresql.Migrate(db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("migration: %w", err)
+		}
+
+		return &Repositories{
+			Songs:     postgresql.NewSongRepo(db, logger),
+			Groups:    postgresql.NewGroupRepo(db, logger),
+			Revisions: postgresql.NewRevisionRepo(db, logger),
+			Tx:        postgresql.NewTransactor(db, logger),
+			Close:     db.Close,
+		}, nil
+
+	case Memory:
+		store := memory.NewStore()
+
+		return &Repositories{
+			Songs:     memory.NewSongRepo(store, logger),
+			Groups:    memory.NewGroupRepo(store, logger),
+			Revisions: memory.NewRevisionRepo(store, logger),
+			Tx:        memory.NewTransactor(store),
+			Close:     func() {},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown storage %q", cfg.Storage)
+}
+
+// openSQLite opens the SQLite database file at DB_URL, creating it if
+// missing.
+func openSQLite(ctx context.Context, cfg config.Config, logger *slog.Logger) (*Repositories, error) {
+	db, err := sqlite.Open(ctx, cfg.DbUrl)
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite: %w", err)
+	}
+	if err := sqlite.Migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migration: %w", err)
+	}
+
+	return &Repositories{
+		Songs:     sqlite.NewSongRepo(db, logger),
+		Groups:    sqlite.NewGroupRepo(db, logger),
+		Revisions: sqlite.NewRevisionRepo(db, logger),
+		Tx:        sqlite.NewTransactor(db, logger),
+		Close:     func() { db.Close() },
+	}, nil
+}
@@ -0,0 +1,124 @@
+package storagetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+	"music/internal/storage"
+)
+
+func testGroups(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	const missing = 1 << 30
+
+	queen, err := r.Groups.Create(ctx, m.GroupParams{Name: "Queen"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queen.ID == 0 || queen.Name != "Queen" {
+		t.Fatalf("created group %+v", queen)
+	}
+	muse, err := r.Groups.Create(ctx, m.GroupParams{Name: "Muse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.Groups.Get(ctx, queen.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != queen {
+		t.Fatalf("group %+v, want %+v", got, queen)
+	}
+	_, err = r.Groups.Get(ctx, missing)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	// songs join existing groups by name
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	if s.GroupID != muse.ID {
+		t.Fatalf("song of group %d, want %d", s.GroupID, muse.ID)
+	}
+
+	blur, err := r.Groups.Update(ctx, queen.ID, m.GroupParams{Name: "Blur"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blur != (models.Group{ID: queen.ID, Name: "Blur"}) {
+		t.Fatalf("updated group %+v", blur)
+	}
+	_, err = r.Groups.Update(ctx, missing, m.GroupParams{Name: "Blur"})
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	// listed by name
+	groups, err := r.Groups.List(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0] != blur || groups[1] != muse {
+		t.Fatalf("groups %v, want %v", groups, []models.Group{blur, muse})
+	}
+	groups, err = r.Groups.List(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0] != muse {
+		t.Fatalf("second page %v, want %v", groups, []models.Group{muse})
+	}
+	groups, err = r.Groups.List(ctx, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups == nil || len(groups) != 0 {
+		t.Fatalf("page past the end %v, want empty", groups)
+	}
+
+	// songs renamed with the group
+	got2, err := r.Songs.GetByID(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got2.Group != "Muse" {
+		t.Fatalf("song of group %q, want Muse", got2.Group)
+	}
+	if _, err := r.Groups.Update(ctx, muse.ID, m.GroupParams{Name: "MUSE"}); err != nil {
+		t.Fatal(err)
+	}
+	got2, err = r.Songs.GetByID(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got2.Group != "MUSE" {
+		t.Fatalf("song of group %q, want MUSE", got2.Group)
+	}
+
+	// groups with songs, even in the trash, are kept
+	assertCode(t, r.Groups.Delete(ctx, muse.ID), internal.ErrorCodeConflict)
+	if err := r.Songs.Delete(ctx, s.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	err = r.Groups.Delete(ctx, muse.ID)
+	assertCode(t, err, internal.ErrorCodeConflict)
+	if !strings.Contains(err.Error(), "trash") {
+		t.Fatalf("error %q does not mention the trash", err)
+	}
+
+	// purged songs release the group
+	if _, err := r.Songs.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Groups.Delete(ctx, muse.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Groups.Delete(ctx, blur.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Groups.Get(ctx, blur.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	assertCode(t, r.Groups.Delete(ctx, blur.ID), internal.ErrorCodeNotFound)
+}
@@ -0,0 +1,209 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+	"music/internal/storage"
+)
+
+func testRevisions(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	if err := r.Revisions.Add(ctx, s.ID, models.RevisionCreate, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := r.Songs.Update(ctx, s.ID, 0, updateParams(s, "They will not force us"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Revisions.Add(ctx, s.ID, models.RevisionUpdate, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// deleted songs keep the history
+	if err := r.Songs.Delete(ctx, s.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Revisions.Add(ctx, s.ID, models.RevisionDelete, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := r.Revisions.List(ctx, s.ID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("%d revisions, want 3", len(revisions))
+	}
+	for i, want := range []struct {
+		version int32
+		action  models.RevisionAction
+		author  string
+	}{
+		{3, models.RevisionDelete, "bob"},
+		{2, models.RevisionUpdate, ""},
+		{1, models.RevisionCreate, "alice"},
+	} {
+		rev := revisions[i]
+		if rev.Version != want.version || rev.Action != want.action || rev.Author != want.author {
+			t.Fatalf("revision %d is %d %q by %q, want %d %q by %q",
+				i, rev.Version, rev.Action, rev.Author, want.version, want.action, want.author)
+		}
+		if rev.CreatedAt.IsZero() {
+			t.Fatalf("revision %d has no time", rev.Version)
+		}
+	}
+
+	first, err := r.Revisions.Get(ctx, s.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, first.Song, s)
+	second, err := r.Revisions.Get(ctx, s.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, second.Song, updated)
+
+	revisions, err = r.Revisions.List(ctx, s.ID, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].Version != 1 {
+		t.Fatalf("second page %v, want revision 1", revisions)
+	}
+
+	_, err = r.Revisions.Get(ctx, s.ID, 4)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	revisions, err = r.Revisions.List(ctx, 1<<30, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revisions == nil || len(revisions) != 0 {
+		t.Fatalf("revisions of a missing song %v, want empty", revisions)
+	}
+}
+
+// testEmptyPatch checks an empty patch keeps the version, so a revision is
+// never recorded twice for it.
+func testEmptyPatch(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	if err := r.Revisions.Add(ctx, s.ID, models.RevisionCreate, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.Songs.Patch(ctx, s.ID, s.Version, m.PatchParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, s)
+
+	// the version is checked all the same
+	_, err = r.Songs.Patch(ctx, s.ID, s.Version+1, m.PatchParams{})
+	assertCode(t, err, internal.ErrorCodePreconditionFailed)
+
+	// revisions are unique by version
+	if err := r.Revisions.Add(ctx, s.ID, models.RevisionUpdate, ""); err == nil {
+		t.Fatal("revision of the same version added twice")
+	}
+	revisions, err := r.Revisions.List(ctx, s.ID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].Action != models.RevisionCreate {
+		t.Fatalf("revisions %v, want the created one", revisions)
+	}
+}
+
+func testTransactions(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	failure := errors.New("failure")
+
+	var s models.Song
+	err := r.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		s, err = r.Songs.Create(ctx, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+		if err != nil {
+			return err
+		}
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("error %v, want %v", err, failure)
+	}
+	_, err = r.Songs.GetByID(ctx, s.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	groups, err := r.Groups.List(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Fatalf("groups %v left by a rolled back transaction", groups)
+	}
+
+	// a failed nested unit rolls back only its own changes
+	var inner models.Song
+	err = r.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		s, err = r.Songs.Create(ctx, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+		if err != nil {
+			return err
+		}
+
+		err = r.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			inner, err = r.Songs.Create(ctx, newSong("Muse", "Starlight", "04.09.2006", "Far away"))
+			if err != nil {
+				return err
+			}
+
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("nested error %v, want %v", err, failure)
+		}
+
+		// a failed repository call does not break the transaction
+		_, err = r.Songs.Create(ctx, newSong("Muse", "UPRISING", "07.09.2009", "Paranoia is in bloom"))
+		assertCode(t, err, internal.ErrorCodeUniqueConstraints)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Songs.GetByID(ctx, s.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Songs.GetByID(ctx, inner.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	// panics roll back and propagate
+	func() {
+		defer func() {
+			if p := recover(); p != failure {
+				t.Fatalf("recovered %v, want %v", p, failure)
+			}
+		}()
+		r.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			name := "Resistance"
+			if _, err := r.Songs.Patch(ctx, s.ID, 0, m.PatchParams{Name: &name}); err != nil {
+				return err
+			}
+			panic(failure)
+		})
+	}()
+	got, err := r.Songs.GetByID(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, s)
+}
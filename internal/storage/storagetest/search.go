@@ -0,0 +1,354 @@
+package storagetest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"music/internal"
+	"music/internal/app/models"
+	"music/internal/storage"
+)
+
+// catalog stores songs for search tests, in the order of ids.
+func catalog(t *testing.T, r *storage.Repositories) []models.Song {
+	t.Helper()
+
+	return []models.Song{
+		mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom\n\nThey will not force us")),
+		mustCreate(t, r, newSong("Queen", "Bohemian Rhapsody", "31.10.1975", "Is this the real life?\n\nIs this just fantasy?")),
+		mustCreate(t, r, newSong("Muse", "Starlight", "04.09.2006", "Far away\n\nMy life, you electrify my life")),
+		mustCreate(t, r, newSong("Blur", "Song 2", "07.04.1997", "Woo-hoo\n\nWhen I feel heavy metal")),
+		mustCreate(t, r, newSong("Queen", "Radio Ga Ga", "23.01.1984", "I'd sit alone and watch your light")),
+	}
+}
+
+func criterion(field string, op models.Operator, values ...any) models.Criterion {
+	return models.Criterion{Field: field, Op: op, Values: values}
+}
+
+func testSearch(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	songs := catalog(t, r)
+	uprising, rhapsody, starlight, song2, radio := songs[0], songs[1], songs[2], songs[3], songs[4]
+
+	tests := []struct {
+		name     string
+		criteria []models.Criterion
+		want     []models.Song
+	}{
+		{"all", nil, []models.Song{song2, uprising, starlight, rhapsody, radio}},
+		{"eq", []models.Criterion{criterion("group_name", models.OpEq, "Queen")}, []models.Song{rhapsody, radio}},
+		{"eq case", []models.Criterion{criterion("group_name", models.OpEq, "queen")}, nil},
+		{"ieq", []models.Criterion{criterion("group_name", models.OpIEq, "qUEEN")}, []models.Song{rhapsody, radio}},
+		{"group id", []models.Criterion{criterion("group_id", models.OpEq, uprising.GroupID)}, []models.Song{uprising, starlight}},
+		{"prefix", []models.Criterion{criterion("song_name", models.OpPrefix, "Radio")}, []models.Song{radio}},
+		{"prefix case", []models.Criterion{criterion("song_name", models.OpPrefix, "radio")}, nil},
+		{"prefix wildcard", []models.Criterion{criterion("song_name", models.OpPrefix, "%")}, nil},
+		{"contains", []models.Criterion{criterion("song_name", models.OpContains, "light")}, []models.Song{starlight}},
+		{"ilike", []models.Criterion{criterion("song_name", models.OpILike, "RHAPS")}, []models.Song{rhapsody}},
+		{"in", []models.Criterion{criterion("song_name", models.OpIn, "Uprising", "Song 2", "Missing")}, []models.Song{song2, uprising}},
+		{"similar", []models.Criterion{criterion("song_name", models.OpSimilar, "Uprsing")}, []models.Song{uprising}},
+		{"text", []models.Criterion{criterion("song_text", models.OpContains, "fantasy")}, []models.Song{rhapsody}},
+		{"date gte", []models.Criterion{criterion("release_date", models.OpGte, date(t, "04.09.2006"))}, []models.Song{uprising, starlight}},
+		{"date lt", []models.Criterion{criterion("release_date", models.OpLt, date(t, "04.09.2006"))}, []models.Song{song2, rhapsody, radio}},
+		{"date eq", []models.Criterion{criterion("release_date", models.OpEq, date(t, "31.10.1975"))}, []models.Song{rhapsody}},
+		{"date range", []models.Criterion{
+			criterion("release_date", models.OpGt, date(t, "31.10.1975")),
+			criterion("release_date", models.OpLte, date(t, "07.04.1997")),
+		}, []models.Song{song2, radio}},
+		{"all criteria", []models.Criterion{
+			criterion("group_name", models.OpEq, "Muse"),
+			criterion("song_name", models.OpPrefix, "Star"),
+		}, []models.Song{starlight}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := models.SearchQuery{Criteria: tt.criteria}
+			got, err := r.Songs.Search(ctx, q, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]int32, len(tt.want))
+			for i, s := range tt.want {
+				want[i] = s.ID
+			}
+			assertIDs(t, got, want...)
+			for i := range got {
+				assertSong(t, got[i], tt.want[i])
+			}
+
+			total, err := r.Songs.Count(ctx, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != len(tt.want) {
+				t.Fatalf("count %d, want %d", total, len(tt.want))
+			}
+		})
+	}
+
+	t.Run("sort", func(t *testing.T) {
+		got, err := r.Songs.Search(ctx, models.SearchQuery{
+			Sort: []models.SortKey{{Field: "release_date", Desc: true}},
+		}, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, got, uprising.ID, starlight.ID, song2.ID, radio.ID, rhapsody.ID)
+
+		got, err = r.Songs.Search(ctx, models.SearchQuery{
+			Sort: []models.SortKey{{Field: "group_name", Desc: true}, {Field: "song_name"}},
+		}, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, got, rhapsody.ID, radio.ID, starlight.ID, uprising.ID, song2.ID)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, q := range []models.SearchQuery{
+			{Criteria: []models.Criterion{criterion("unknown", models.OpEq, "x")}},
+			{Criteria: []models.Criterion{criterion("song_name", "regexp", "x")}},
+			{Criteria: []models.Criterion{criterion("song_name", models.OpEq, "x", "y")}},
+			{Criteria: []models.Criterion{criterion("group_id", models.OpPrefix, uprising.GroupID)}},
+			{Sort: []models.SortKey{{Field: "song_text"}}},
+		} {
+			_, err := r.Songs.Search(ctx, q, 0, 10)
+			assertCode(t, err, internal.ErrorCodeInvalidArgument)
+		}
+	})
+}
+
+func testSearchPaging(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	songs := catalog(t, r)
+	uprising, rhapsody, starlight, song2, radio := songs[0], songs[1], songs[2], songs[3], songs[4]
+	all := []int32{song2.ID, uprising.ID, starlight.ID, rhapsody.ID, radio.ID}
+
+	for _, tt := range []struct {
+		page, perPage int
+		want          []int32
+	}{
+		{0, 2, all[0:2]},
+		{1, 2, all[2:4]},
+		{2, 2, all[4:5]},
+		{3, 2, nil},
+		{0, 5, all},
+		{1, 5, nil},
+		{0, 100, all},
+		{4, 1, all[4:5]},
+	} {
+		got, err := r.Songs.Search(ctx, models.SearchQuery{}, tt.page, tt.perPage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil {
+			t.Fatalf("page %d of %d is nil, want empty", tt.page, tt.perPage)
+		}
+		assertIDs(t, got, tt.want...)
+	}
+
+	// the count does not depend on the page
+	q := models.SearchQuery{Criteria: []models.Criterion{criterion("group_name", models.OpEq, "Muse")}}
+	got, err := r.Songs.Search(ctx, q, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, got, starlight.ID)
+	total, err := r.Songs.Count(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Fatalf("count %d, want 2", total)
+	}
+}
+
+func testSearchKeyset(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	songs := catalog(t, r)
+	uprising, rhapsody, starlight, song2, radio := songs[0], songs[1], songs[2], songs[3], songs[4]
+
+	// the default order is by group name and id
+	q := models.SearchQuery{After: []any{"Muse", uprising.ID}}
+	got, err := r.Songs.Search(ctx, q, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, got, starlight.ID, rhapsody.ID, radio.ID)
+
+	q = models.SearchQuery{
+		Sort:  []models.SortKey{{Field: "release_date", Desc: true}},
+		After: []any{starlight.ReleaseDate, starlight.ID},
+	}
+	got, err = r.Songs.Search(ctx, q, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, got, song2.ID, radio.ID)
+
+	q = models.SearchQuery{
+		Criteria: []models.Criterion{criterion("group_name", models.OpEq, "Queen")},
+		Sort:     []models.SortKey{{Field: "song_name"}},
+		After:    []any{"Bohemian Rhapsody", rhapsody.ID},
+	}
+	got, err = r.Songs.Search(ctx, q, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, got, radio.ID)
+
+	q = models.SearchQuery{After: []any{"Muse"}}
+	_, err = r.Songs.Search(ctx, q, 0, 10)
+	assertCode(t, err, internal.ErrorCodeInvalidArgument)
+}
+
+func testSearchText(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	songs := catalog(t, r)
+	uprising, rhapsody, starlight := songs[0], songs[1], songs[2]
+
+	got, err := r.Songs.SearchText(ctx, "fantasy", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("found %d songs, want 1", len(got))
+	}
+	assertSong(t, got[0].Song, rhapsody)
+	if got[0].Rank <= 0 {
+		t.Fatalf("rank %v, want positive", got[0].Rank)
+	}
+	if !strings.Contains(got[0].Headline, "<b>fantasy</b>") {
+		t.Fatalf("headline %q does not highlight the word", got[0].Headline)
+	}
+
+	// words of the query are all found in a verse
+	got, err = r.Songs.SearchText(ctx, "life", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("found %d songs, want 2", len(got))
+	}
+	got, err = r.Songs.SearchText(ctx, "real life", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != rhapsody.ID {
+		t.Fatalf("found %d songs, want %d", len(got), rhapsody.ID)
+	}
+
+	// web search syntax: phrases, or and exclusions
+	for _, tt := range []struct {
+		query string
+		want  []int32
+	}{
+		{`"real life"`, []int32{rhapsody.ID}},
+		{`"life real"`, nil},
+		{`life -real`, []int32{starlight.ID}},
+		{`life -"real life"`, []int32{starlight.ID}},
+		{`life -"life real"`, []int32{rhapsody.ID, starlight.ID}},
+		{`fantasy or electrify`, []int32{rhapsody.ID, starlight.ID}},
+		{`-fantasy`, nil},
+	} {
+		got, err := r.Songs.SearchText(ctx, tt.query, 0, 10)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		ids := make([]int32, len(got))
+		for i, s := range got {
+			ids[i] = s.ID
+		}
+		slices.Sort(ids)
+		if !slices.Equal(ids, tt.want) {
+			t.Fatalf("%s: found %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+
+	got, err = r.Songs.SearchText(ctx, "life", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("second page has %d songs, want 1", len(got))
+	}
+
+	got, err = r.Songs.SearchText(ctx, "submarine", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Fatalf("found %v, want empty", got)
+	}
+
+	// deleted songs are not found
+	if err := r.Songs.Delete(ctx, uprising.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, err = r.Songs.SearchText(ctx, "paranoia", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("found %d deleted songs", len(got))
+	}
+
+	// the index follows text changes
+	text := "Far away\n\nThe ship is taking me far away"
+	if _, err := r.Songs.Update(ctx, starlight.ID, 0, updateParams(starlight, text)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = r.Songs.SearchText(ctx, "ship", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != starlight.ID {
+		t.Fatalf("updated text not found: %v", got)
+	}
+}
+
+func testSuggest(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	songs := catalog(t, r)
+	uprising, rhapsody := songs[0], songs[1]
+
+	got, err := r.Songs.Suggest(ctx, "Uprisin", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || got[0].ID != uprising.ID {
+		t.Fatalf("suggested %v, want %d first", got, uprising.ID)
+	}
+	if got[0].GroupID != uprising.GroupID || got[0].Group != "Muse" || got[0].Name != "Uprising" {
+		t.Fatalf("suggestion %+v does not match the song", got[0])
+	}
+	if got[0].Score <= 0 || got[0].Score > 1 {
+		t.Fatalf("score %v out of (0, 1]", got[0].Score)
+	}
+
+	// group names are matched as well
+	got, err = r.Songs.Suggest(ctx, "queen", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != rhapsody.ID {
+		t.Fatalf("suggested %v, want only %d", got, rhapsody.ID)
+	}
+
+	if err := r.Songs.Delete(ctx, uprising.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, err = r.Songs.Suggest(ctx, "Uprisin", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sg := range got {
+		if sg.ID == uprising.ID {
+			t.Fatal("deleted song suggested")
+		}
+	}
+}
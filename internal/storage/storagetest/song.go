@@ -0,0 +1,422 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+	"music/internal/storage"
+)
+
+func testCreate(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	text := "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh\nYou set my soul alight"
+
+	s := mustCreate(t, r, newSong("Muse", "Supermassive Black Hole", "16.07.2006", text))
+	if s.ID == 0 || s.GroupID == 0 {
+		t.Fatalf("created song %+v has no ids", s)
+	}
+	assertSong(t, s, models.Song{
+		ID:          s.ID,
+		GroupID:     s.GroupID,
+		Group:       "Muse",
+		Name:        "Supermassive Black Hole",
+		ReleaseDate: date(t, "16.07.2006"),
+		Text:        text,
+		Link:        "https://example.org/Supermassive_Black_Hole",
+		Version:     1,
+	})
+
+	got, err := r.Songs.GetByID(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, s)
+
+	gotText, err := r.Songs.SelectText(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotText != text {
+		t.Fatalf("text %q, want %q", gotText, text)
+	}
+
+	// the group is found by name
+	other := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	if other.GroupID != s.GroupID {
+		t.Fatalf("group %d, want %d", other.GroupID, s.GroupID)
+	}
+	if other.ID == s.ID {
+		t.Fatalf("songs share id %d", s.ID)
+	}
+
+	// or by id, it takes precedence over the name
+	p := newSong("Queen", "Starlight", "04.09.2006", "Far away")
+	p.GroupID = s.GroupID
+	byID := mustCreate(t, r, p)
+	if byID.GroupID != s.GroupID || byID.Group != "Muse" {
+		t.Fatalf("song of group %d %q, want %d %q", byID.GroupID, byID.Group, s.GroupID, "Muse")
+	}
+
+	p = newSong("Muse", "Resistance", "14.09.2009", "Love is our resistance")
+	p.GroupID = 1 << 30
+	_, err = r.Songs.Create(ctx, p)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	_, err = r.Songs.Create(ctx, newSong("Muse", "Resistance", "2009-09-14", "Love is our resistance"))
+	assertCode(t, err, internal.ErrorCodeInvalidArgument)
+}
+
+func testNotFound(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	const missing = 1 << 30
+	mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+
+	_, err := r.Songs.GetByID(ctx, missing)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	_, err = r.Songs.SelectText(ctx, missing)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	_, err = r.Songs.Update(ctx, missing, 0, m.UpdateParams{
+		Group: "Muse", Name: "Uprising", ReleaseDate: "07.09.2009", Text: "text", Link: "link",
+	})
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	name := "Uprising"
+	_, err = r.Songs.Patch(ctx, missing, 0, m.PatchParams{Name: &name})
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	assertCode(t, r.Songs.Delete(ctx, missing, 0), internal.ErrorCodeNotFound)
+
+	_, err = r.Songs.Restore(ctx, missing)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+}
+
+// testSections checks lyrics are stored as sections: line breaks and
+// annotations are normalized, the search does not see annotations.
+func testSections(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	text := "[Intro]\r\nOoh\r\n\r\n\r\nOoh baby, don't you know I suffer?\r\n[chorus 1]\r\nYou set my soul alight\r\n"
+	want := "[Intro]\nOoh\n\nOoh baby, don't you know I suffer?\n\n[Chorus]\nYou set my soul alight"
+
+	s := mustCreate(t, r, newSong("Muse", "Supermassive Black Hole", "16.07.2006", text))
+	if s.Text != want {
+		t.Fatalf("created text %q, want %q", s.Text, want)
+	}
+
+	got, err := r.Songs.SelectText(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("text %q, want %q", got, want)
+	}
+
+	matches, err := r.Songs.SearchText(ctx, "chorus", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("annotation found by search: %+v", matches)
+	}
+
+	updated, err := r.Songs.Update(ctx, s.ID, s.Version, updateParams(s, "[Bridge]\r\nSupermassive black hole"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Text != "[Bridge]\nSupermassive black hole" {
+		t.Fatalf("updated text %q", updated.Text)
+	}
+	got, err = r.Songs.SelectText(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != updated.Text {
+		t.Fatalf("text %q, want %q", got, updated.Text)
+	}
+
+	// an annotation alone stays in the text and does not type the next verse
+	text = "[Chorus]\r\nYou set my soul alight\r\n\r\n[Chorus x2]\r\n\r\nOoh\r\n\r\n[Outro]"
+	want = "[Chorus]\nYou set my soul alight\n\n[Chorus x2]\n\nOoh\n\n[Outro]"
+	updated, err = r.Songs.Update(ctx, s.ID, updated.Version, updateParams(s, text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Text != want {
+		t.Fatalf("updated text %q, want %q", updated.Text, want)
+	}
+	got, err = r.Songs.SelectText(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("text %q, want %q", got, want)
+	}
+}
+
+func testUpdate(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+
+	p := m.UpdateParams{
+		Group:       "Queen",
+		Name:        "Bohemian Rhapsody",
+		ReleaseDate: "31.10.1975",
+		Text:        "Is this the real life?\n\nIs this just fantasy?",
+		Link:        "https://example.org/queen",
+	}
+	updated, err := r.Songs.Update(ctx, s.ID, s.Version, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GroupID == s.GroupID {
+		t.Fatal("song stays in the old group")
+	}
+	want := models.Song{
+		ID:          s.ID,
+		GroupID:     updated.GroupID,
+		Group:       "Queen",
+		Name:        "Bohemian Rhapsody",
+		ReleaseDate: date(t, "31.10.1975"),
+		Text:        p.Text,
+		Link:        p.Link,
+		Version:     s.Version + 1,
+	}
+	assertSong(t, updated, want)
+
+	got, err := r.Songs.GetByID(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, want)
+
+	// the version is checked unless zero
+	_, err = r.Songs.Update(ctx, s.ID, s.Version, p)
+	assertCode(t, err, internal.ErrorCodePreconditionFailed)
+	updated, err = r.Songs.Update(ctx, s.ID, 0, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version != want.Version+1 {
+		t.Fatalf("version %d, want %d", updated.Version, want.Version+1)
+	}
+
+	p.GroupID = s.GroupID
+	updated, err = r.Songs.Update(ctx, s.ID, 0, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GroupID != s.GroupID || updated.Group != "Muse" {
+		t.Fatalf("song of group %d %q, want %d %q", updated.GroupID, updated.Group, s.GroupID, "Muse")
+	}
+
+	p.GroupID = 1 << 30
+	_, err = r.Songs.Update(ctx, s.ID, 0, p)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	p.GroupID = 0
+	p.ReleaseDate = "1975-10-31"
+	_, err = r.Songs.Update(ctx, s.ID, 0, p)
+	assertCode(t, err, internal.ErrorCodeInvalidArgument)
+}
+
+func testPatch(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+
+	// an empty patch changes nothing
+	got, err := r.Songs.Patch(ctx, s.ID, s.Version, m.PatchParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, s)
+
+	name := "Starlight"
+	got, err = r.Songs.Patch(ctx, s.ID, s.Version, m.PatchParams{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := s
+	want.Name = name
+	want.Version++
+	assertSong(t, got, want)
+
+	text := "Far away\n\nThe ship is taking me far away"
+	release := "04.09.2006"
+	got, err = r.Songs.Patch(ctx, s.ID, want.Version, m.PatchParams{Text: &text, ReleaseDate: &release})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Text = text
+	want.ReleaseDate = date(t, release)
+	want.Version++
+	assertSong(t, got, want)
+
+	got, err = r.Songs.GetByID(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, want)
+
+	group := "Queen"
+	got, err = r.Songs.Patch(ctx, s.ID, 0, m.PatchParams{Group: &group})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Group != group || got.GroupID == s.GroupID {
+		t.Fatalf("song of group %d %q, want a new group %q", got.GroupID, got.Group, group)
+	}
+
+	_, err = r.Songs.Patch(ctx, s.ID, s.Version, m.PatchParams{Name: &name})
+	assertCode(t, err, internal.ErrorCodePreconditionFailed)
+
+	missing := int32(1 << 30)
+	_, err = r.Songs.Patch(ctx, s.ID, 0, m.PatchParams{GroupID: &missing})
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	release = "2006-09-04"
+	_, err = r.Songs.Patch(ctx, s.ID, 0, m.PatchParams{ReleaseDate: &release})
+	assertCode(t, err, internal.ErrorCodeInvalidArgument)
+}
+
+func testDelete(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	kept := mustCreate(t, r, newSong("Muse", "Starlight", "04.09.2006", "Far away"))
+
+	assertCode(t, r.Songs.Delete(ctx, s.ID, s.Version+1), internal.ErrorCodePreconditionFailed)
+	if err := r.Songs.Delete(ctx, s.ID, s.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := r.Songs.GetByID(ctx, s.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	_, err = r.Songs.SelectText(ctx, s.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	assertCode(t, r.Songs.Delete(ctx, s.ID, 0), internal.ErrorCodeNotFound)
+	name := "Resistance"
+	_, err = r.Songs.Patch(ctx, s.ID, 0, m.PatchParams{Name: &name})
+	assertCode(t, err, internal.ErrorCodeNotFound)
+
+	songs, err := r.Songs.Search(ctx, models.SearchQuery{}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertIDs(t, songs, kept.ID)
+	total, err := r.Songs.Count(ctx, models.SearchQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("count %d, want 1", total)
+	}
+
+	trash, err := r.Songs.Trash(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 {
+		t.Fatalf("trash has %d songs, want 1", len(trash))
+	}
+	want := s
+	want.Version++
+	assertSong(t, trash[0].Song, want)
+	if trash[0].DeletedAt.IsZero() {
+		t.Fatal("trashed song has no deletion time")
+	}
+
+	restored, err := r.Songs.Restore(ctx, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Version++
+	assertSong(t, restored, want)
+
+	_, err = r.Songs.Restore(ctx, s.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	trash, err = r.Songs.Trash(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 0 {
+		t.Fatalf("trash has %d songs after restore", len(trash))
+	}
+}
+
+func testPurge(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	kept := mustCreate(t, r, newSong("Muse", "Starlight", "04.09.2006", "Far away"))
+	if err := r.Songs.Delete(ctx, s.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := r.Songs.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("purged %d songs deleted an hour later", n)
+	}
+
+	n, err = r.Songs.Purge(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("purged %d songs, want 1", n)
+	}
+
+	_, err = r.Songs.Restore(ctx, s.ID)
+	assertCode(t, err, internal.ErrorCodeNotFound)
+	if _, err := r.Songs.GetByID(ctx, kept.ID); err != nil {
+		t.Fatalf("song out of the trash purged: %v", err)
+	}
+}
+
+func testUnique(t *testing.T, r *storage.Repositories) {
+	ctx := context.Background()
+	s := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+
+	// names are compared ignoring case
+	_, err := r.Songs.Create(ctx, newSong("Muse", "UPRISING", "07.09.2009", "Paranoia is in bloom"))
+	assertDuplicate(t, err, s.ID)
+
+	// other groups may have the name
+	other := mustCreate(t, r, newSong("Queen", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+
+	_, err = r.Songs.Update(ctx, other.ID, 0, m.UpdateParams{
+		Group: "Muse", Name: "uprising", ReleaseDate: "07.09.2009", Text: "text", Link: "link",
+	})
+	assertDuplicate(t, err, s.ID)
+
+	group := "Muse"
+	_, err = r.Songs.Patch(ctx, other.ID, 0, m.PatchParams{Group: &group})
+	assertDuplicate(t, err, s.ID)
+
+	// a failed change leaves the song as it was
+	got, err := r.Songs.GetByID(ctx, other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSong(t, got, other)
+
+	// the song keeps its own name
+	name := "Uprising"
+	if _, err := r.Songs.Patch(ctx, s.ID, 0, m.PatchParams{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+
+	// deleted songs do not hold their names, but can not be restored while
+	// the name is taken
+	if err := r.Songs.Delete(ctx, s.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	taken := mustCreate(t, r, newSong("Muse", "Uprising", "07.09.2009", "Paranoia is in bloom"))
+	_, err = r.Songs.Restore(ctx, s.ID)
+	assertDuplicate(t, err, taken.ID)
+}
@@ -0,0 +1,164 @@
+// Package storagetest is the conformance suite of the repositories used by
+// services, every storage backend must pass it. A backend runs it from its
+// tests:
+//
+//	func TestRepositories(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) *storage.Repositories {
+//			return openEmptyStorage(t)
+//		})
+//	}
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"music/internal"
+	"music/internal/app/models"
+	m "music/internal/rest/models"
+	"music/internal/storage"
+)
+
+// Opener returns repositories of an empty storage, it is called for every
+// test of the suite. Close of the repositories is called on test cleanup.
+type Opener func(t *testing.T) *storage.Repositories
+
+// Run runs the whole suite against the storage returned by open.
+func Run(t *testing.T, open Opener) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r *storage.Repositories)
+	}{
+		{"Create", testCreate},
+		{"NotFound", testNotFound},
+		{"Update", testUpdate},
+		{"Patch", testPatch},
+		{"Delete", testDelete},
+		{"Purge", testPurge},
+		{"Unique", testUnique},
+		{"Sections", testSections},
+		{"Search", testSearch},
+		{"SearchPaging", testSearchPaging},
+		{"SearchKeyset", testSearchKeyset},
+		{"SearchText", testSearchText},
+		{"Suggest", testSuggest},
+		{"Groups", testGroups},
+		{"Revisions", testRevisions},
+		{"EmptyPatch", testEmptyPatch},
+		{"Transactions", testTransactions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := open(t)
+			t.Cleanup(r.Close)
+			tt.fn(t, r)
+		})
+	}
+}
+
+// newSong returns create params of a song with a link made of its name.
+func newSong(group, name, date, text string) m.CreateParams {
+	return m.CreateParams{
+		Group:       group,
+		Name:        name,
+		ReleaseDate: date,
+		Text:        text,
+		Link:        "https://example.org/" + strings.ReplaceAll(name, " ", "_"),
+	}
+}
+
+// updateParams returns params replacing the song text, other fields stay.
+func updateParams(s models.Song, text string) m.UpdateParams {
+	return m.UpdateParams{
+		GroupID:     s.GroupID,
+		Group:       s.Group,
+		Name:        s.Name,
+		ReleaseDate: s.ReleaseDate.Format("02.01.2006"),
+		Text:        text,
+		Link:        s.Link,
+	}
+}
+
+// mustCreate stores the song and fails the test on error.
+func mustCreate(t *testing.T, r *storage.Repositories, p m.CreateParams) models.Song {
+	t.Helper()
+
+	s, err := r.Songs.Create(context.Background(), p)
+	if err != nil {
+		t.Fatalf("create %q: %v", p.Name, err)
+	}
+
+	return s
+}
+
+// date parses a date in the 02.01.2006 format of params.
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse("02.01.2006", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+// assertCode checks err carries the error code.
+func assertCode(t *testing.T, err error, want internal.ErrorCode) {
+	t.Helper()
+
+	var e *internal.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("error %v has no code, want code %d", err, want)
+	}
+	if e.Code() != want {
+		t.Fatalf("error %v has code %d, want %d", err, e.Code(), want)
+	}
+}
+
+// assertDuplicate checks err reports a duplicate of the song existing.
+func assertDuplicate(t *testing.T, err error, existing int32) {
+	t.Helper()
+
+	assertCode(t, err, internal.ErrorCodeUniqueConstraints)
+	var dup *internal.DuplicateError
+	if !errors.As(err, &dup) {
+		t.Fatalf("error %v does not wrap the duplicate", err)
+	}
+	if dup.ID != existing {
+		t.Fatalf("duplicate of %d, want %d", dup.ID, existing)
+	}
+}
+
+// assertSong compares songs field by field, dates by the instant.
+func assertSong(t *testing.T, got, want models.Song) {
+	t.Helper()
+
+	if got.ID != want.ID || got.GroupID != want.GroupID || got.Group != want.Group ||
+		got.Name != want.Name || got.Text != want.Text || got.Link != want.Link ||
+		got.Version != want.Version || !got.ReleaseDate.Equal(want.ReleaseDate) {
+		t.Fatalf("song\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// assertIDs checks the songs have the ids in the order.
+func assertIDs(t *testing.T, songs []models.Song, want ...int32) {
+	t.Helper()
+
+	got := make([]int32, len(songs))
+	for i, s := range songs {
+		got[i] = s.ID
+	}
+	if len(got) != len(want) {
+		t.Fatalf("songs %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("songs %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package trgm measures text similarity by trigrams the way the pg_trgm
+// extension of PostgreSQL does, for storages without it.
+package trgm
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// SimilarityThreshold is the least similarity of text matched by
+	// models.OpSimilar, as pg_trgm.similarity_threshold.
+	SimilarityThreshold = 0.3
+	// WordSimilarityThreshold is the least word similarity of suggestions, as
+	// pg_trgm.word_similarity_threshold.
+	WordSimilarityThreshold = 0.6
+)
+
+// Words splits text into lower case words of letters and digits.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams returns the set of trigrams of text the way pg_trgm makes them:
+// every word is padded with two spaces in front and one after.
+func trigrams(text string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, w := range Words(text) {
+		runes := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// common counts trigrams found in both sets.
+func common(a, b map[string]struct{}) int {
+	var n int
+	for t := range a {
+		if _, ok := b[t]; ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Similarity is the share of trigrams two texts have in common.
+func Similarity(a, b string) float32 {
+	ta, tb := trigrams(a), trigrams(b)
+
+	n := common(ta, tb)
+	all := len(ta) + len(tb) - n
+	if all == 0 {
+		return 0
+	}
+
+	return float32(n) / float32(all)
+}
+
+// WordSimilarity is the share of trigrams of text found in the words of b,
+// it is high for words of b typed with few typos or unfinished.
+func WordSimilarity(text, b string) float32 {
+	ta, tb := trigrams(text), trigrams(b)
+	if len(ta) == 0 {
+		return 0
+	}
+
+	return float32(common(ta, tb)) / float32(len(ta))
+}
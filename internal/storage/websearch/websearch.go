@@ -0,0 +1,121 @@
+// Package websearch parses web search style lyrics queries the way
+// websearch_to_tsquery of PostgreSQL does, for storages without it.
+package websearch
+
+import (
+	"slices"
+	"strings"
+
+	"music/internal/storage/trgm"
+)
+
+// Phrase is a sequence of lower case words matched next to each other.
+type Phrase []string
+
+// Query is a parsed query: text matches when every group has a matching
+// phrase and no excluded phrase matches.
+type Query struct {
+	// All groups of alternative phrases joined by or
+	All [][]Phrase
+	// Not phrases prefixed by a minus
+	Not []Phrase
+}
+
+// Parse parses words and "quoted phrases" which must all match, or between
+// them matches either and -word or -"phrase" excludes text with it.
+func Parse(text string) Query {
+	var (
+		q  Query
+		or bool
+	)
+	for len(text) > 0 {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if text == "" {
+			break
+		}
+
+		neg := text[0] == '-'
+		if neg {
+			text = text[1:]
+		}
+
+		var token string
+		if strings.HasPrefix(text, `"`) {
+			token, text, _ = strings.Cut(text[1:], `"`)
+		} else {
+			i := strings.IndexAny(text, " \t\r\n")
+			if i < 0 {
+				i = len(text)
+			}
+			token, text = text[:i], text[i:]
+		}
+
+		words := trgm.Words(token)
+		if len(words) == 0 {
+			continue
+		}
+		if !neg && len(words) == 1 && words[0] == "or" && !strings.Contains(token, `"`) && len(q.All) > 0 {
+			or = true
+			continue
+		}
+
+		switch {
+		case neg:
+			q.Not = append(q.Not, words)
+		case or:
+			q.All[len(q.All)-1] = append(q.All[len(q.All)-1], words)
+			or = false
+		default:
+			q.All = append(q.All, []Phrase{words})
+		}
+	}
+
+	return q
+}
+
+// Empty tells whether the query has nothing to match.
+func (q Query) Empty() bool {
+	return len(q.All) == 0
+}
+
+// Match tells whether the words of a text satisfy the query.
+func (q Query) Match(words []string) bool {
+	if q.Empty() {
+		return false
+	}
+	for _, group := range q.All {
+		if !slices.ContainsFunc(group, func(p Phrase) bool { return p.In(words) }) {
+			return false
+		}
+	}
+	for _, p := range q.Not {
+		if p.In(words) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Words returns words of all phrases to match, excluded ones left out.
+func (q Query) Words() []string {
+	var words []string
+	for _, group := range q.All {
+		for _, p := range group {
+			words = append(words, p...)
+		}
+	}
+
+	return words
+}
+
+// In tells whether the phrase occurs in words.
+func (p Phrase) In(words []string) bool {
+	for i := 0; i+len(p) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(p)], p) {
+			return true
+		}
+	}
+
+	return false
+}